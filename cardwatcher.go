@@ -0,0 +1,348 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io/ioutil"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yi-jiayu/nationstates-secretary/internal/atomicfile"
+	"github.com/yi-jiayu/nationstates-secretary/nationstates"
+)
+
+// maxPriceHistory is the number of trades kept for each watched card.
+const maxPriceHistory = 200
+
+// defaultMaxDeckRequests is the default number of cards in our deck whose
+// markets are checked for bids in each poll.
+const defaultMaxDeckRequests = 10
+
+// CardWatch is a card whose market is watched for prices crossing thresholds.
+type CardWatch struct {
+	ID     int `json:"id"`
+	Season int `json:"season"`
+	// BidAbove alerts when the highest bid reaches it, if it is positive.
+	BidAbove float64 `json:"bid_above"`
+	// AskBelow alerts when the lowest ask falls to it, if it is positive.
+	AskBelow float64 `json:"ask_below"`
+}
+
+func cardKey(id, season int) string {
+	return fmt.Sprintf("%d:%d", id, season)
+}
+
+// cardHistory is the state kept for a watched card.
+type cardHistory struct {
+	Trades []nationstates.Trade `json:"trades"`
+	// BidAlerted and AskAlerted record whether the thresholds are currently
+	// crossed, so that an alert is only sent when they are first crossed.
+	BidAlerted bool `json:"bid_alerted"`
+	AskAlerted bool `json:"ask_alerted"`
+}
+
+type cardWatcherState struct {
+	Cards map[string]*cardHistory `json:"cards"`
+	// Bids holds the bids on cards in our deck which have been alerted, keyed
+	// by card, bidder and time. Bids are forgotten once they leave the market
+	// or the card leaves our deck.
+	Bids map[string]bool `json:"bids"`
+}
+
+// CardWatcher polls the card market for watched cards and the cards in
+// Nation's deck, and calls Alert when prices cross thresholds or someone
+// bids on our cards.
+type CardWatcher struct {
+	PollInterval time.Duration
+	Client       *nationstates.Client
+	// Nation is the nation whose deck is watched for bids. Bids are not
+	// watched if it is empty.
+	Nation string
+	// MaxDeckRequests limits how many cards in the deck are checked for bids
+	// in each poll, so that a large deck does not use up the API rate limit.
+	// The next cards are checked in the following poll, so every card is
+	// checked in turn.
+	MaxDeckRequests int
+	Cards           []CardWatch
+	HistoryFile     string
+	Alert           func(text string)
+
+	mu    sync.Mutex
+	state cardWatcherState
+	// deckOffset is the position in the deck at which the next poll starts
+	// checking for bids.
+	deckOffset int
+}
+
+// NewCardWatcher returns a CardWatcher, restoring its price history from
+// historyFile.
+func NewCardWatcher(client *nationstates.Client, nation string, cards []CardWatch, historyFile string) (*CardWatcher, error) {
+	w := &CardWatcher{
+		PollInterval:    time.Hour,
+		Client:          client,
+		Nation:          nation,
+		MaxDeckRequests: defaultMaxDeckRequests,
+		Cards:           cards,
+		HistoryFile:     historyFile,
+	}
+	data, err := ioutil.ReadFile(historyFile)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err == nil {
+		err = json.Unmarshal(data, &w.state)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if w.state.Cards == nil {
+		w.state.Cards = make(map[string]*cardHistory)
+	}
+	if w.state.Bids == nil {
+		w.state.Bids = make(map[string]bool)
+	}
+	return w, nil
+}
+
+// History returns the recorded trades of a watched card, oldest first.
+func (w *CardWatcher) History(id, season int) []nationstates.Trade {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	h := w.state.Cards[cardKey(id, season)]
+	if h == nil {
+		return nil
+	}
+	return append([]nationstates.Trade(nil), h.Trades...)
+}
+
+func (w *CardWatcher) poll() {
+	for _, watch := range w.Cards {
+		ctx, cancel := context.WithTimeout(context.Background(), apiTimeout)
+		card, err := w.Client.GetCardContext(ctx, watch.ID, watch.Season, []nationstates.Shard{
+			nationstates.ShardInfo,
+			nationstates.ShardMarkets,
+			nationstates.ShardTrades,
+		})
+		cancel()
+		if err != nil {
+			log.Println(err)
+			continue
+		}
+		for _, alert := range w.checkCard(watch, card) {
+			w.alert(alert)
+		}
+	}
+	if w.Nation != "" {
+		w.pollDeck()
+	}
+	w.mu.Lock()
+	err := w.saveLocked()
+	w.mu.Unlock()
+	if err != nil {
+		log.Println(err)
+	}
+}
+
+// pollDeck alerts on new bids for the cards in our deck.
+func (w *CardWatcher) pollDeck() {
+	ctx, cancel := context.WithTimeout(context.Background(), apiTimeout)
+	deck, _, err := w.Client.GetDeckContext(ctx, w.Nation)
+	cancel()
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	for _, c := range w.deckBatch(deck) {
+		ctx, cancel := context.WithTimeout(context.Background(), apiTimeout)
+		card, err := w.Client.GetCardContext(ctx, c.ID, c.Season, []nationstates.Shard{nationstates.ShardInfo, nationstates.ShardMarkets})
+		cancel()
+		if err != nil {
+			log.Println(err)
+			continue
+		}
+		for _, alert := range w.checkBids(card) {
+			w.alert(alert)
+		}
+	}
+}
+
+// deckBatch returns the distinct cards in deck which should be checked for
+// bids in this poll, and forgets the bids on cards which have left the deck.
+func (w *CardWatcher) deckBatch(deck []nationstates.Card) []nationstates.Card {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var cards []nationstates.Card
+	inDeck := make(map[string]bool)
+	for _, c := range deck {
+		key := cardKey(c.ID, c.Season)
+		if !inDeck[key] {
+			inDeck[key] = true
+			cards = append(cards, c)
+		}
+	}
+	for key := range w.state.Bids {
+		if !inDeck[bidCardKey(key)] {
+			delete(w.state.Bids, key)
+		}
+	}
+	if w.MaxDeckRequests <= 0 || len(cards) <= w.MaxDeckRequests {
+		return cards
+	}
+	start := w.deckOffset % len(cards)
+	w.deckOffset = start + w.MaxDeckRequests
+	batch := append([]nationstates.Card(nil), cards[start:]...)
+	batch = append(batch, cards[:start]...)
+	return batch[:w.MaxDeckRequests]
+}
+
+// checkCard records new trades of card and returns alerts for thresholds
+// which it has crossed.
+func (w *CardWatcher) checkCard(watch CardWatch, card nationstates.Card) []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	key := cardKey(watch.ID, watch.Season)
+	h := w.state.Cards[key]
+	if h == nil {
+		h = new(cardHistory)
+		w.state.Cards[key] = h
+	}
+	var last int
+	if len(h.Trades) > 0 {
+		last = h.Trades[len(h.Trades)-1].Timestamp
+	}
+	// several trades can happen in the same second, so trades at the time of
+	// the last recorded trade are only new if they have not been recorded
+	recorded := make(map[tradeKey]bool)
+	for i := len(h.Trades) - 1; i >= 0 && h.Trades[i].Timestamp == last; i-- {
+		recorded[keyOfTrade(h.Trades[i])] = true
+	}
+	// trades are returned newest first
+	for i := len(card.Trades) - 1; i >= 0; i-- {
+		t := card.Trades[i]
+		// gifts are recorded as trades at no price and say nothing about
+		// what the card is worth
+		if t.Price == 0 || t.Timestamp < last || recorded[keyOfTrade(t)] {
+			continue
+		}
+		recorded[keyOfTrade(t)] = true
+		h.Trades = append(h.Trades, t)
+	}
+	if len(h.Trades) > maxPriceHistory {
+		h.Trades = h.Trades[len(h.Trades)-maxPriceHistory:]
+	}
+
+	var alerts []string
+	bid, ask := bestPrices(card.Markets)
+	crossed := watch.BidAbove > 0 && bid >= watch.BidAbove
+	if crossed && !h.BidAlerted {
+		alerts = append(alerts, fmt.Sprintf("Highest bid for %s is %.2f, at or above %.2f.", formatCard(card), bid, watch.BidAbove))
+	}
+	h.BidAlerted = crossed
+	crossed = watch.AskBelow > 0 && ask > 0 && ask <= watch.AskBelow
+	if crossed && !h.AskAlerted {
+		alerts = append(alerts, fmt.Sprintf("Lowest ask for %s is %.2f, at or below %.2f.", formatCard(card), ask, watch.AskBelow))
+	}
+	h.AskAlerted = crossed
+	return alerts
+}
+
+// tradeKey identifies a trade of a card.
+type tradeKey struct {
+	Timestamp     int
+	Buyer, Seller string
+	Price         float64
+}
+
+func keyOfTrade(t nationstates.Trade) tradeKey {
+	return tradeKey{Timestamp: t.Timestamp, Buyer: t.Buyer, Seller: t.Seller, Price: t.Price}
+}
+
+// checkBids returns alerts for bids on card which have not been alerted yet.
+func (w *CardWatcher) checkBids(card nationstates.Card) []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var alerts []string
+	current := make(map[string]bool)
+	for _, order := range card.Markets {
+		if order.Type != nationstates.MarketBid || containsName([]string{w.Nation}, order.Nation) {
+			continue
+		}
+		key := fmt.Sprintf("%s:%s:%d", cardKey(card.ID, card.Season), order.Nation, order.Timestamp)
+		current[key] = true
+		if w.state.Bids[key] {
+			continue
+		}
+		w.state.Bids[key] = true
+		alerts = append(alerts, fmt.Sprintf("%s bid %.2f for %s in our deck.", html.EscapeString(order.Nation), order.Price, formatCard(card)))
+	}
+	// bids which have been withdrawn or filled will not come back
+	for key := range w.state.Bids {
+		if bidCardKey(key) == cardKey(card.ID, card.Season) && !current[key] {
+			delete(w.state.Bids, key)
+		}
+	}
+	return alerts
+}
+
+// bidCardKey returns the key of the card in the key of an alerted bid.
+func bidCardKey(bidKey string) string {
+	parts := strings.SplitN(bidKey, ":", 3)
+	if len(parts) < 2 {
+		return bidKey
+	}
+	return parts[0] + ":" + parts[1]
+}
+
+func (w *CardWatcher) alert(text string) {
+	if w.Alert != nil {
+		w.Alert(text)
+	}
+}
+
+func (w *CardWatcher) saveLocked() error {
+	data, err := json.Marshal(w.state)
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(w.HistoryFile, data)
+}
+
+func (w *CardWatcher) Start() {
+	w.poll()
+	ticker := time.NewTicker(w.PollInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		w.poll()
+	}
+}
+
+// bestPrices returns the highest bid and lowest ask in orders, or zero if
+// there are none.
+func bestPrices(orders []nationstates.MarketOrder) (bid, ask float64) {
+	for _, order := range orders {
+		switch order.Type {
+		case nationstates.MarketBid:
+			if order.Price > bid {
+				bid = order.Price
+			}
+		case nationstates.MarketAsk:
+			if ask == 0 || order.Price < ask {
+				ask = order.Price
+			}
+		}
+	}
+	return bid, ask
+}
+
+func formatCard(card nationstates.Card) string {
+	name := card.Name
+	if name == "" {
+		name = fmt.Sprintf("card %d", card.ID)
+	}
+	return fmt.Sprintf(`<a href="https://www.nationstates.net/page=deck/card=%d/season=%d">%s (S%d)</a>`, card.ID, card.Season, html.EscapeString(name), card.Season)
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/yi-jiayu/nationstates-secretary/nationstates"
+)
+
+func newTestCardWatcher(t *testing.T, cards ...CardWatch) *CardWatcher {
+	t.Helper()
+	w, err := NewCardWatcher(nil, "testlandia", cards, filepath.Join(t.TempDir(), "cards.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return w
+}
+
+func bid(nation string, price float64, timestamp int) nationstates.MarketOrder {
+	return nationstates.MarketOrder{Nation: nation, Price: price, Timestamp: timestamp, Type: nationstates.MarketBid}
+}
+
+func ask(nation string, price float64, timestamp int) nationstates.MarketOrder {
+	return nationstates.MarketOrder{Nation: nation, Price: price, Timestamp: timestamp, Type: nationstates.MarketAsk}
+}
+
+func TestBestPrices(t *testing.T) {
+	if highest, lowest := bestPrices(nil); highest != 0 || lowest != 0 {
+		t.Fatalf("got %.2f and %.2f for no orders, wanted 0 and 0", highest, lowest)
+	}
+	highest, lowest := bestPrices([]nationstates.MarketOrder{
+		bid("a", 1.5, 1), bid("b", 2.25, 2), ask("c", 4, 3), ask("d", 3.5, 4),
+	})
+	if highest != 2.25 || lowest != 3.5 {
+		t.Fatalf("got %.2f and %.2f, wanted 2.25 and 3.50", highest, lowest)
+	}
+}
+
+func TestCheckCardThresholds(t *testing.T) {
+	watch := CardWatch{ID: 1, Season: 2, BidAbove: 2, AskBelow: 1}
+	w := newTestCardWatcher(t, watch)
+	card := nationstates.Card{ID: 1, Season: 2, Name: "Testlandia"}
+	steps := []struct {
+		markets []nationstates.MarketOrder
+		alerts  int
+	}{
+		{[]nationstates.MarketOrder{bid("a", 1.5, 1), ask("b", 1.5, 1)}, 0},
+		// crossing the thresholds alerts once
+		{[]nationstates.MarketOrder{bid("a", 2, 2), ask("b", 1, 2)}, 2},
+		{[]nationstates.MarketOrder{bid("a", 3, 3), ask("b", 0.5, 3)}, 0},
+		// dropping back and crossing again alerts again
+		{[]nationstates.MarketOrder{bid("a", 1, 4)}, 0},
+		{[]nationstates.MarketOrder{bid("a", 2.5, 5)}, 1},
+	}
+	for i, step := range steps {
+		card.Markets = step.markets
+		if alerts := w.checkCard(watch, card); len(alerts) != step.alerts {
+			t.Fatalf("got alerts %q in step %d, wanted %d", alerts, i, step.alerts)
+		}
+	}
+}
+
+func TestCheckCardHistory(t *testing.T) {
+	watch := CardWatch{ID: 1, Season: 2}
+	w := newTestCardWatcher(t, watch)
+	card := nationstates.Card{ID: 1, Season: 2, Trades: []nationstates.Trade{{Price: 2, Timestamp: 2}, {Price: 1, Timestamp: 1}}}
+	w.checkCard(watch, card)
+	card.Trades = append([]nationstates.Trade{{Buyer: "a", Price: 3, Timestamp: 3}}, card.Trades...)
+	w.checkCard(watch, card)
+	// another trade in the same second and a gift
+	card.Trades = append([]nationstates.Trade{{Buyer: "b", Price: 0, Timestamp: 4}, {Buyer: "b", Price: 3, Timestamp: 3}}, card.Trades...)
+	w.checkCard(watch, card)
+	history := w.History(1, 2)
+	if len(history) != 4 || history[0].Timestamp != 1 || history[2].Buyer != "a" || history[3].Buyer != "b" {
+		t.Fatalf("got history %+v, wanted trades 1, 2 and both trades at 3 in order without the gift", history)
+	}
+}
+
+func TestCheckBids(t *testing.T) {
+	w := newTestCardWatcher(t)
+	card := nationstates.Card{ID: 1, Season: 2, Markets: []nationstates.MarketOrder{
+		bid("buyer", 1, 100), bid("testlandia", 2, 101), bid("Testlandia", 2, 103), ask("seller", 3, 102),
+	}}
+	if alerts := w.checkBids(card); len(alerts) != 1 {
+		t.Fatalf("got alerts %q, wanted 1 for the other nation's bid", alerts)
+	}
+	if alerts := w.checkBids(card); len(alerts) != 0 {
+		t.Fatalf("got alerts %q for the same bid, wanted none", alerts)
+	}
+	card.Markets = []nationstates.MarketOrder{bid("buyer", 1.5, 200)}
+	if alerts := w.checkBids(card); len(alerts) != 1 {
+		t.Fatalf("got alerts %q for a new bid, wanted 1", alerts)
+	}
+	if len(w.state.Bids) != 1 {
+		t.Fatalf("got alerted bids %v, wanted the withdrawn bid to be forgotten", w.state.Bids)
+	}
+	w.deckBatch([]nationstates.Card{{ID: 3, Season: 2}})
+	if len(w.state.Bids) != 0 {
+		t.Fatalf("got alerted bids %v, wanted bids on cards which left the deck to be forgotten", w.state.Bids)
+	}
+}
+
+func TestDeckBatch(t *testing.T) {
+	w := newTestCardWatcher(t)
+	w.MaxDeckRequests = 2
+	deck := []nationstates.Card{{ID: 1}, {ID: 1}, {ID: 2}, {ID: 3}}
+	var checked []int
+	for i := 0; i < 3; i++ {
+		for _, c := range w.deckBatch(deck) {
+			checked = append(checked, c.ID)
+		}
+	}
+	want := []int{1, 2, 3, 1, 2, 3}
+	if len(checked) != len(want) {
+		t.Fatalf("got checked cards %v, wanted %v", checked, want)
+	}
+	for i := range want {
+		if checked[i] != want[i] {
+			t.Fatalf("got checked cards %v, wanted %v", checked, want)
+		}
+	}
+}
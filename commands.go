@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+
+	"github.com/yi-jiayu/nationstates-secretary/nationstates"
+)
+
+const commandsHelp = `Commands:
+/rmb <text> - post to the regional message board
+/dispatch <title>
+<text> - publish a factbook dispatch
+/giftcard <card id> <season> <nation> - gift a trading card
+/link <nation> - link your own nation`
+
+// splitCommand splits a chat message into the command name and its
+// arguments.
+func splitCommand(text string) (string, string) {
+	name, args := text, ""
+	if i := strings.IndexAny(text, " \n"); i >= 0 {
+		name, args = text[:i], strings.TrimSpace(text[i+1:])
+	}
+	// commands may be addressed to the bot as /command@bot in group chats
+	if i := strings.Index(name, "@"); i >= 0 {
+		name = name[:i]
+	}
+	return name, args
+}
+
+// parseCommand converts a chat message into a private command for nation.
+func parseCommand(ctx context.Context, client *nationstates.Client, nation, text string) (nationstates.Command, error) {
+	name, args := splitCommand(text)
+	switch name {
+	case "/rmb":
+		if args == "" {
+			return nil, errors.New("usage: /rmb <text>")
+		}
+		n, err := client.GetNationContext(ctx, nation, []nationstates.Shard{nationstates.ShardRegion})
+		if err != nil {
+			return nil, err
+		}
+		return nationstates.RMBPostCommand{Region: n.Region, Text: args}, nil
+	case "/dispatch":
+		lines := strings.SplitN(args, "\n", 2)
+		if len(lines) < 2 || strings.TrimSpace(lines[0]) == "" || strings.TrimSpace(lines[1]) == "" {
+			return nil, errors.New("usage: /dispatch <title>, followed by the text on the next line")
+		}
+		return nationstates.DispatchCommand{
+			Action:      nationstates.DispatchAdd,
+			Title:       strings.TrimSpace(lines[0]),
+			Text:        strings.TrimSpace(lines[1]),
+			Category:    nationstates.DispatchFactbook,
+			Subcategory: nationstates.DispatchFactbookOverview,
+		}, nil
+	case "/giftcard":
+		fields := strings.Fields(args)
+		if len(fields) != 3 {
+			return nil, errors.New("usage: /giftcard <card id> <season> <nation>")
+		}
+		cardID, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid card id: %s", fields[0])
+		}
+		season, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid season: %s", fields[1])
+		}
+		return nationstates.GiftCardCommand{CardID: cardID, Season: season, To: fields[2]}, nil
+	}
+	return nil, errors.New(commandsHelp)
+}
+
+// handleCommand executes a private command sent to the bot and replies with
+// the result.
+func handleCommand(ctx context.Context, client *nationstates.Client, nation, token string, chatID int, text string) error {
+	var reply string
+	cmd, err := parseCommand(ctx, client, nation, text)
+	if err == nil {
+		reply, err = client.ExecuteContext(ctx, nation, cmd)
+	}
+	if err != nil {
+		var cmdErr *nationstates.CommandError
+		if errors.As(err, &cmdErr) {
+			reply = cmdErr.Message
+		} else {
+			reply = err.Error()
+		}
+	}
+	return sendMessage(token, chatID, html.EscapeString(reply))
+}
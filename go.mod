@@ -1,3 +1,3 @@
 module github.com/yi-jiayu/nationstates-secretary
 
-go 1.13
+go 1.16
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/yi-jiayu/nationstates-secretary/internal/atomicfile"
+	"github.com/yi-jiayu/nationstates-secretary/nationstates"
+)
+
+// pendingLinkExpiry is how long a user has to verify a nation after sending
+// /link.
+const pendingLinkExpiry = time.Hour
+
+// pendingLink is a nation a Telegram user has claimed but not yet verified.
+type pendingLink struct {
+	Nation  string
+	Token   string
+	Created time.Time
+}
+
+// Links records which nation each Telegram user has verified that they own.
+type Links struct {
+	Path string
+
+	mu      sync.Mutex
+	pending map[int]pendingLink
+	nations map[int]string
+}
+
+// LoadLinks returns the links saved at path.
+func LoadLinks(path string) (*Links, error) {
+	l := &Links{
+		Path:    path,
+		pending: make(map[int]pendingLink),
+		nations: make(map[int]string),
+	}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return l, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(data, &l.nations)
+	if err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// start begins linking nation to a Telegram user and returns the token the
+// user must get a checksum for.
+func (l *Links) start(userID int, nation string) (string, error) {
+	b := make([]byte, 16)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(b)
+	now := time.Now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for id, p := range l.pending {
+		if now.Sub(p.Created) > pendingLinkExpiry {
+			delete(l.pending, id)
+		}
+	}
+	l.pending[userID] = pendingLink{Nation: nation, Token: token, Created: now}
+	return token, nil
+}
+
+// finish verifies checksum for the nation a Telegram user is linking, and
+// saves the link if it is valid. The nation is empty if the user has no
+// pending link or it has expired.
+func (l *Links) finish(ctx context.Context, client *nationstates.Client, userID int, checksum string) (string, bool, error) {
+	l.mu.Lock()
+	p, ok := l.pending[userID]
+	if ok && time.Since(p.Created) > pendingLinkExpiry {
+		delete(l.pending, userID)
+		ok = false
+	}
+	l.mu.Unlock()
+	if !ok {
+		return "", false, nil
+	}
+	verified, err := client.VerifyContext(ctx, p.Nation, checksum, p.Token)
+	if err != nil || !verified {
+		return p.Nation, false, err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.pending, userID)
+	l.nations[userID] = p.Nation
+	data, err := json.Marshal(l.nations)
+	if err != nil {
+		return p.Nation, true, err
+	}
+	return p.Nation, true, atomicfile.WriteFile(l.Path, data)
+}
+
+// handleLink handles the /link and /verify commands, which any Telegram user
+// may send to link their own nation.
+func handleLink(ctx context.Context, client *nationstates.Client, links *Links, token string, m *Message) error {
+	name, args := splitCommand(m.Text)
+	var reply string
+	switch name {
+	case "/link":
+		if args == "" {
+			reply = html.EscapeString("Usage: /link <nation>")
+			break
+		}
+		verifyToken, err := links.start(m.From.ID, args)
+		if err != nil {
+			return err
+		}
+		reply = fmt.Sprintf(`To link %s, log in to it and open <a href="%s">this page</a>, then send /verify followed by the code shown there.`,
+			html.EscapeString(args), html.EscapeString(nationstates.VerifyURL(verifyToken)))
+	case "/verify":
+		if args == "" {
+			reply = html.EscapeString("Usage: /verify <code>")
+			break
+		}
+		nation, ok, err := links.finish(ctx, client, m.From.ID, args)
+		switch {
+		case err != nil:
+			reply = fmt.Sprintf("Could not verify %s: %s", html.EscapeString(nation), html.EscapeString(err.Error()))
+		case nation == "":
+			reply = "Send /link with the name of your nation first. Codes expire after an hour."
+		case !ok:
+			reply = fmt.Sprintf("That code does not verify %s. Try again or send /link to start over.", html.EscapeString(nation))
+		default:
+			reply = fmt.Sprintf("Linked %s.", html.EscapeString(nation))
+		}
+	}
+	return sendMessage(token, m.Chat.ID, reply)
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yi-jiayu/nationstates-secretary/nationstates"
+)
+
+func newVerifyServer(t *testing.T, checksum string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("a") != "verify" || q.Get("token") == "" {
+			t.Errorf("got query %s", r.URL.RawQuery)
+		}
+		if q.Get("checksum") == checksum {
+			w.Write([]byte("1\n"))
+		} else {
+			w.Write([]byte("0\n"))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestLinks(t *testing.T) {
+	client := nationstates.NewClient(nationstates.WithBaseURL(newVerifyServer(t, "good").URL))
+	path := filepath.Join(t.TempDir(), "links.json")
+	l, err := LoadLinks(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if nation, _, err := l.finish(ctx, client, 1, "good"); err != nil || nation != "" {
+		t.Fatalf("got nation %q and error %v without /link, wanted none", nation, err)
+	}
+	if _, err := l.start(1, "testlandia"); err != nil {
+		t.Fatal(err)
+	}
+	if nation, ok, err := l.finish(ctx, client, 1, "bad"); err != nil || nation != "testlandia" || ok {
+		t.Fatalf("got %q, %t, %v for a bad checksum", nation, ok, err)
+	}
+	if nation, ok, err := l.finish(ctx, client, 1, "good"); err != nil || nation != "testlandia" || !ok {
+		t.Fatalf("got %q, %t, %v for a good checksum", nation, ok, err)
+	}
+
+	l, err = LoadLinks(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := l.nations[1]; got != "testlandia" {
+		t.Fatalf("got nation %q after reload, wanted testlandia", got)
+	}
+}
+
+func TestLinksExpiry(t *testing.T) {
+	client := nationstates.NewClient(nationstates.WithBaseURL(newVerifyServer(t, "good").URL))
+	l, err := LoadLinks(filepath.Join(t.TempDir(), "links.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.pending[1] = pendingLink{Nation: "testlandia", Token: "token", Created: time.Now().Add(-pendingLinkExpiry - time.Minute)}
+	l.pending[2] = pendingLink{Nation: "otherland", Token: "token", Created: time.Now().Add(-pendingLinkExpiry - time.Minute)}
+	if nation, ok, err := l.finish(context.Background(), client, 1, "good"); err != nil || nation != "" || ok {
+		t.Fatalf("got %q, %t, %v for an expired link, wanted no nation", nation, ok, err)
+	}
+	if _, ok := l.pending[1]; ok {
+		t.Fatal("got expired link still pending after /verify")
+	}
+	if _, err := l.start(3, "thirdland"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := l.pending[2]; ok {
+		t.Fatal("got expired link still pending after another /link")
+	}
+}
+
+func TestHandleLink(t *testing.T) {
+	var replies []string
+	telegram := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got, want := r.URL.Path, "/bottoken/sendMessage"; got != want {
+			t.Errorf("got path %q, wanted %q", got, want)
+		}
+		var req SendMessageRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Error(err)
+			return
+		}
+		replies = append(replies, req.Text)
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer telegram.Close()
+	defer func(u string) { telegramBaseURL = u }(telegramBaseURL)
+	telegramBaseURL = telegram.URL
+
+	client := nationstates.NewClient(nationstates.WithBaseURL(newVerifyServer(t, "good").URL))
+	links, err := LoadLinks(filepath.Join(t.TempDir(), "links.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	for _, text := range []string{"/link", "/link <b>testlandia</b>", "/verify good"} {
+		err := handleLink(ctx, client, links, "token", &Message{Text: text, Chat: Chat{ID: 2}, From: &User{ID: 1}})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(replies) != 3 {
+		t.Fatalf("got %d replies, wanted 3", len(replies))
+	}
+	if want := "Usage: /link &lt;nation&gt;"; replies[0] != want {
+		t.Errorf("got usage %q, wanted %q", replies[0], want)
+	}
+	if !strings.Contains(replies[1], "To link &lt;b&gt;testlandia&lt;/b&gt;") || !strings.Contains(replies[1], "page=verify_login") {
+		t.Errorf("got reply %q to /link", replies[1])
+	}
+	if want := "Linked &lt;b&gt;testlandia&lt;/b&gt;."; replies[2] != want {
+		t.Errorf("got reply %q to /verify, wanted %q", replies[2], want)
+	}
+	if got := links.nations[1]; got != "<b>testlandia</b>" {
+		t.Errorf("got linked nation %q", got)
+	}
+}
@@ -2,9 +2,11 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"html"
 	"log"
 	"math"
 	"net/http"
@@ -13,9 +15,11 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 	"unicode"
 
+	"github.com/yi-jiayu/nationstates-secretary/internal/atomicfile"
 	"github.com/yi-jiayu/nationstates-secretary/nationstates"
 )
 
@@ -40,15 +44,63 @@ func (o *InMemoryOffsetter) SetOffset(offset int) {
 	o.offset = offset
 }
 
+// apiTimeout bounds how long a single NationStates request made while
+// handling a Telegram update may take.
+const apiTimeout = 30 * time.Second
+
+// Defaults for retrying notice delivery.
+const (
+	defaultMaxAttempts  = 5
+	defaultRetryBackoff = time.Second
+)
+
+// defaultAlertThreshold is how many polls may fail in a row before the
+// operator is alerted, and minPollBackoff is the delay before polling again
+// after the first failure.
+const (
+	defaultAlertThreshold = 3
+	minPollBackoff        = time.Minute
+)
+
+// streamTriggerDelay is how long to wait after an event in the happenings
+// stream before polling for the notices it causes.
+const streamTriggerDelay = 5 * time.Second
+
 type Notifier struct {
 	PollInterval     time.Duration
 	Client           *nationstates.Client
 	Nation           string
-	AdditionalShards []string
-	Callback         func(notice nationstates.Notice, nation nationstates.Nation)
-	Offsetter        Offsetter
+	AdditionalShards []nationstates.Shard
+	// Callback delivers a notice. If it returns an error, delivery is retried
+	// and the offset is not advanced past the notice until it succeeds,
+	// unless telegramRejected reports true for the error, in which case the
+	// notice is skipped and Alert is called.
+	Callback  func(notice nationstates.Notice, nation nationstates.Nation) error
+	Offsetter Offsetter
+	// Timeout bounds each poll. If it is zero, PollInterval is used.
+	Timeout time.Duration
+	// Triggers causes an immediate poll whenever it receives, for example
+	// when the happenings stream reports activity involving the nation.
+	Triggers <-chan struct{}
+	// MaxAttempts is how many times delivering a notice is attempted in a
+	// single poll. It defaults to defaultMaxAttempts.
+	MaxAttempts int
+	// RetryBackoff is the delay before the first retry, which doubles after
+	// each attempt. It defaults to defaultRetryBackoff.
+	RetryBackoff time.Duration
+	// Alert is called with a message for the operator when polling has
+	// failed AlertThreshold times in a row or the credentials are rejected,
+	// and again when polling recovers.
+	Alert func(text string)
+	// AlertThreshold is how many consecutive polls may fail before Alert is
+	// called. It defaults to defaultAlertThreshold.
+	AlertThreshold int
 
 	ticker *time.Ticker
+	// failures counts consecutive failed polls, and alerted records whether
+	// Alert has been called since the last successful poll.
+	failures int
+	alerted  bool
 }
 
 type SendMessageRequest struct {
@@ -70,38 +122,212 @@ type InlineKeyboardButton struct {
 
 type TelegramResponse struct {
 	Ok          bool   `json:"ok"`
+	ErrorCode   int    `json:"error_code"`
 	Description string `json:"description"`
 }
 
-func (n Notifier) poll() {
+// TelegramAPIError is returned when the Telegram Bot API refuses a request.
+type TelegramAPIError struct {
+	Code        int
+	Description string
+}
+
+func (e *TelegramAPIError) Error() string {
+	return e.Description
+}
+
+// telegramRejected reports whether err means that Telegram refused a message
+// itself, for example because its HTML could not be parsed or it was too
+// long, so that sending it again would fail in the same way. Unauthorized,
+// forbidden and not found errors mean the token or chat is wrong, which
+// affects every message, so they are not counted.
+func telegramRejected(err error) bool {
+	var tgErr *TelegramAPIError
+	if !errors.As(err, &tgErr) {
+		return false
+	}
+	switch tgErr.Code {
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusTooManyRequests:
+		return false
+	}
+	return tgErr.Code >= 400 && tgErr.Code < 500
+}
+
+// poll delivers new notices and returns an error if they could not be
+// fetched or delivered, so that the notices are fetched again after a
+// backoff. Notices which Telegram rejects are skipped instead.
+func (n Notifier) poll() error {
 	log.Println("polling for notices")
-	nation, err := n.Client.GetNation(n.Nation, append(n.AdditionalShards, "notices"), map[string]interface{}{"from": n.Offsetter.Offset()})
+	timeout := n.Timeout
+	if timeout == 0 {
+		timeout = n.PollInterval
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	nation, err := n.Client.GetNationContext(ctx, n.Nation, append(n.AdditionalShards, nationstates.ShardNotices), nationstates.From(n.Offsetter.Offset()))
 	if err != nil {
-		return
+		return err
+	}
+	notices := nation.Notices
+	if len(notices) == 0 {
+		return nil
 	}
-	if notices := nation.Notices; len(notices) > 0 {
-		log.Printf("got %d new notices\n", len(notices))
-		n.Offsetter.SetOffset(notices[0].Timestamp + 1)
-		for i := 0; i < len(notices); i++ {
-			n.Callback(notices[len(notices)-i-1], nation)
+	log.Printf("got %d new notices\n", len(notices))
+	// notices are returned newest first
+	for i := len(notices) - 1; i >= 0; i-- {
+		notice := notices[i]
+		err := n.deliver(notice, nation)
+		if err != nil && telegramRejected(err) {
+			// sending the notice again would fail in the same way, so skip it
+			// instead of holding up every later notice
+			log.Printf("skipping notice %q: %v", notice.Title, err)
+			n.alert(fmt.Sprintf("Skipped the notice <strong>%s</strong> because Telegram rejected it: %s", html.EscapeString(notice.Title), html.EscapeString(err.Error())))
+		} else if err != nil {
+			return fmt.Errorf("delivering notice %q: %w", notice.Title, err)
+		}
+		// the offset is a timestamp, so it can only move past a notice once
+		// every notice with the same timestamp has been delivered
+		if i == 0 || notices[i-1].Timestamp != notice.Timestamp {
+			n.Offsetter.SetOffset(notice.Timestamp + 1)
 		}
 	}
+	return nil
+}
+
+// deliver calls Callback for notice, retrying with exponential backoff if it
+// fails with an error which telegramRejected does not report as permanent.
+func (n Notifier) deliver(notice nationstates.Notice, nation nationstates.Nation) error {
+	attempts := n.MaxAttempts
+	if attempts <= 0 {
+		attempts = defaultMaxAttempts
+	}
+	backoff := n.RetryBackoff
+	if backoff <= 0 {
+		backoff = defaultRetryBackoff
+	}
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = n.Callback(notice, nation)
+		if err == nil || attempt >= attempts || telegramRejected(err) {
+			return err
+		}
+		log.Printf("delivering notice %q (attempt %d): %v", notice.Title, attempt, err)
+		time.Sleep(backoff)
+		backoff *= 2
+	}
 }
 func (n *Notifier) Start() {
 	if n.ticker != nil {
 		n.ticker.Stop()
 	}
-	n.poll()
+	n.handlePollError(n.poll())
 	n.ticker = time.NewTicker(n.PollInterval)
-	for range n.ticker.C {
-		n.poll()
+	for {
+		var timer *time.Timer
+		var retry <-chan time.Time
+		if n.failures > 0 {
+			timer = time.NewTimer(n.backoff())
+			retry = timer.C
+		}
+		select {
+		case <-n.ticker.C:
+		case <-n.Triggers:
+		case <-retry:
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		n.handlePollError(n.poll())
 	}
 }
 
-func (r SendMessageRequest) Do(token string) error {
-	u := fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage", token)
+// pollErrorKind classifies why a poll failed.
+type pollErrorKind int
+
+const (
+	// pollErrorTransient errors, such as NationStates being down or the rate
+	// limit being exceeded, are expected to go away by themselves.
+	pollErrorTransient pollErrorKind = iota
+	// pollErrorAuth errors mean the password or autologin token was
+	// rejected, and need the operator to update the credentials.
+	pollErrorAuth
+	// pollErrorNotFound errors mean the nation does not exist, probably
+	// because it ceased to exist.
+	pollErrorNotFound
+)
+
+func classifyPollError(err error) pollErrorKind {
+	switch {
+	case errors.Is(err, nationstates.ErrForbidden), errors.Is(err, nationstates.ErrPinExpired):
+		return pollErrorAuth
+	case errors.Is(err, nationstates.ErrNotFound):
+		return pollErrorNotFound
+	}
+	return pollErrorTransient
+}
+
+// handlePollError records the outcome of a poll and alerts the operator if
+// polling has been failing for too long or cannot succeed without them.
+func (n *Notifier) handlePollError(err error) {
+	if err == nil {
+		if n.alerted {
+			n.alert(fmt.Sprintf("Polling for notices recovered after %d failures.", n.failures))
+		}
+		n.failures = 0
+		n.alerted = false
+		return
+	}
+	n.failures++
+	kind := classifyPollError(err)
+	log.Printf("polling for notices failed (%d in a row): %v", n.failures, err)
+	if n.alerted {
+		return
+	}
+	threshold := n.AlertThreshold
+	if threshold <= 0 {
+		threshold = defaultAlertThreshold
+	}
+	switch {
+	case kind == pollErrorAuth:
+		n.alert(fmt.Sprintf("NationStates rejected the credentials for %s. Update the password or autologin token.", html.EscapeString(n.Nation)))
+	case kind == pollErrorNotFound:
+		n.alert(fmt.Sprintf("NationStates could not find %s. It may have ceased to exist.", html.EscapeString(n.Nation)))
+	case n.failures >= threshold:
+		n.alert(fmt.Sprintf("Polling for notices has failed %d times in a row: %s", n.failures, html.EscapeString(err.Error())))
+	default:
+		return
+	}
+	n.alerted = true
+}
+
+func (n *Notifier) alert(text string) {
+	if n.Alert != nil {
+		n.Alert(text)
+	}
+}
+
+// backoff returns how long to wait before polling again after a failure. It
+// doubles with each consecutive failure, but is never longer than
+// PollInterval.
+func (n *Notifier) backoff() time.Duration {
+	d := minPollBackoff
+	for i := 1; i < n.failures && d < n.PollInterval; i++ {
+		d *= 2
+	}
+	if d > n.PollInterval {
+		d = n.PollInterval
+	}
+	return d
+}
+
+// telegramBaseURL is the address of the Telegram Bot API.
+var telegramBaseURL = "https://api.telegram.org"
+
+// callTelegram calls a Telegram Bot API method with v as its parameters.
+func callTelegram(token, method string, v interface{}) error {
+	u := fmt.Sprintf("%s/bot%s/%s", telegramBaseURL, token, method)
 	var body bytes.Buffer
-	err := json.NewEncoder(&body).Encode(r)
+	err := json.NewEncoder(&body).Encode(v)
 	if err != nil {
 		return err
 	}
@@ -121,7 +347,41 @@ func (r SendMessageRequest) Do(token string) error {
 		return err
 	}
 	if !tgRes.Ok {
-		return errors.New(tgRes.Description)
+		return &TelegramAPIError{Code: tgRes.ErrorCode, Description: tgRes.Description}
+	}
+	return nil
+}
+
+func (r SendMessageRequest) Do(token string) error {
+	return callTelegram(token, "sendMessage", r)
+}
+
+type SendPhotoRequest struct {
+	ChatID    int    `json:"chat_id"`
+	Photo     string `json:"photo"`
+	Caption   string `json:"caption"`
+	ParseMode string `json:"parse_mode"`
+}
+
+func (r SendPhotoRequest) Do(token string) error {
+	return callTelegram(token, "sendPhoto", r)
+}
+
+// telegramRequest is a Telegram Bot API request which can be sent with a
+// bot token.
+type telegramRequest interface {
+	Do(token string) error
+}
+
+// optionalRequest is a request whose failure is logged and ignored.
+type optionalRequest struct {
+	telegramRequest
+}
+
+func (r optionalRequest) Do(token string) error {
+	err := r.telegramRequest.Do(token)
+	if err != nil {
+		log.Println(err)
 	}
 	return nil
 }
@@ -135,16 +395,24 @@ func sendMessage(token string, chatID int, text string) error {
 }
 
 func sendMessageWithInlineKeyboard(token string, chatID int, text string, buttons [][]InlineKeyboardButton) error {
-	replyMarkup, err := json.Marshal(InlineKeybardMarkup{InlineKeyboard: buttons})
+	req, err := messageWithInlineKeyboard(chatID, text, buttons)
 	if err != nil {
 		return err
 	}
+	return req.Do(token)
+}
+
+func messageWithInlineKeyboard(chatID int, text string, buttons [][]InlineKeyboardButton) (SendMessageRequest, error) {
+	replyMarkup, err := json.Marshal(InlineKeybardMarkup{InlineKeyboard: buttons})
+	if err != nil {
+		return SendMessageRequest{}, err
+	}
 	return SendMessageRequest{
 		ChatID:      chatID,
 		Text:        text,
 		ParseMode:   "HTML",
 		ReplyMarkup: string(replyMarkup),
-	}.Do(token)
+	}, nil
 }
 
 func getIssueID(notice nationstates.Notice) int {
@@ -161,26 +429,59 @@ func indexOfIssueWithID(issues []nationstates.Issue, id int) int {
 	return -1
 }
 
-func sendIssue(token string, chatID int, notice nationstates.Notice, issues []nationstates.Issue) error {
-	id := getIssueID(notice)
-	index := indexOfIssueWithID(issues, id)
-	if index < 0 {
-		return nil
+// issueCredits credits the authors and editor of an issue.
+func issueCredits(issue nationstates.Issue) string {
+	var credits []string
+	if issue.Author != "" {
+		credits = append(credits, "Written by "+html.EscapeString(issue.Author))
+	}
+	if issue.Editor != "" {
+		credits = append(credits, "edited by "+html.EscapeString(issue.Editor))
+	}
+	return strings.Join(credits, ", ")
+}
+
+// issueMessages returns the messages which present an issue: its
+// illustration, the issue itself and one message for each option.
+func issueMessages(chatID int, notice nationstates.Notice, issue nationstates.Issue) ([]telegramRequest, error) {
+	var messages []telegramRequest
+	if pictures := issue.PictureURLs(); len(pictures) > 0 {
+		// the illustration is a nicety, so send the issue even if it fails
+		messages = append(messages, optionalRequest{SendPhotoRequest{
+			ChatID:    chatID,
+			Photo:     pictures[0],
+			Caption:   fmt.Sprintf("<strong>%s</strong>", html.EscapeString(issue.Title)),
+			ParseMode: "HTML",
+		}})
+	}
+	text := fmt.Sprintf("<strong>New Issue: %s</strong>\n%s", html.EscapeString(issue.Title), html.EscapeString(issue.Text))
+	if credits := issueCredits(issue); credits != "" {
+		text += "\n\n<em>" + credits + "</em>"
 	}
-	issue := issues[index]
-	text := fmt.Sprintf("<strong>New Issue: %s</strong>\n%s", issue.Title, issue.Text)
 	u := "https://www.nationstates.net/" + notice.URL
-	err := sendMessageWithInlineKeyboard(token, chatID, text, [][]InlineKeyboardButton{
+	dismissData, err := json.Marshal(CallbackData{
+		Action:  "dismissIssue",
+		IssueID: issue.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	message, err := messageWithInlineKeyboard(chatID, text, [][]InlineKeyboardButton{
 		{
 			InlineKeyboardButton{
 				Text: "View on NationStates",
 				URL:  u,
 			},
+			InlineKeyboardButton{
+				Text:         "Dismiss",
+				CallbackData: string(dismissData),
+			},
 		},
 	})
 	if err != nil {
-		return err
+		return nil, err
 	}
+	messages = append(messages, message)
 	for _, option := range issue.Options {
 		data, err := json.Marshal(CallbackData{
 			Action:   "answerIssue",
@@ -188,9 +489,9 @@ func sendIssue(token string, chatID int, notice nationstates.Notice, issues []na
 			OptionID: option.ID,
 		})
 		if err != nil {
-			return err
+			return nil, err
 		}
-		err = sendMessageWithInlineKeyboard(token, chatID, option.Text, [][]InlineKeyboardButton{
+		message, err := messageWithInlineKeyboard(chatID, html.EscapeString(option.Text), [][]InlineKeyboardButton{
 			{
 				InlineKeyboardButton{
 					Text:         "Accept",
@@ -199,14 +500,57 @@ func sendIssue(token string, chatID int, notice nationstates.Notice, issues []na
 			},
 		})
 		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, message)
+	}
+	return messages, nil
+}
+
+// issueSender sends issues, which take several messages. It remembers how
+// many messages of an issue have been sent, so that retrying a notice
+// resumes where the last attempt stopped instead of repeating them.
+type issueSender struct {
+	token  string
+	chatID int
+	// sent maps issue IDs to the number of their messages which have been
+	// sent, for issues which were only partly sent.
+	sent map[int]int
+}
+
+func newIssueSender(token string, chatID int) *issueSender {
+	return &issueSender{token: token, chatID: chatID, sent: make(map[int]int)}
+}
+
+func (s *issueSender) send(notice nationstates.Notice, issues []nationstates.Issue) error {
+	id := getIssueID(notice)
+	index := indexOfIssueWithID(issues, id)
+	if index < 0 {
+		return nil
+	}
+	issue := issues[index]
+	messages, err := issueMessages(s.chatID, notice, issue)
+	if err != nil {
+		return err
+	}
+	for i := s.sent[issue.ID]; i < len(messages); i++ {
+		err := messages[i].Do(s.token)
+		if err != nil {
+			if telegramRejected(err) {
+				// the notice will be skipped rather than retried
+				delete(s.sent, issue.ID)
+			} else {
+				s.sent[issue.ID] = i
+			}
 			return err
 		}
 	}
+	delete(s.sent, issue.ID)
 	return nil
 }
 
 func answerCallbackQuery(token, id string) error {
-	u := fmt.Sprintf("https://api.telegram.org/bot%s/answerCallbackQuery", token)
+	u := fmt.Sprintf("%s/bot%s/answerCallbackQuery", telegramBaseURL, token)
 	params := url.Values{}
 	params.Add("callback_query_id", id)
 	res, err := http.PostForm(u, params)
@@ -225,18 +569,16 @@ func answerCallbackQuery(token, id string) error {
 	return nil
 }
 
-func newCallback(token string, chatID int) func(notice nationstates.Notice, nation nationstates.Nation) {
-	return func(notice nationstates.Notice, nation nationstates.Nation) {
+func newCallback(token string, chatID int) func(notice nationstates.Notice, nation nationstates.Nation) error {
+	issues := newIssueSender(token, chatID)
+	return func(notice nationstates.Notice, nation nationstates.Nation) error {
 		switch notice.Type {
 		case nationstates.NoticeIssue:
-			err := sendIssue(token, chatID, notice, nation.Issues)
-			if err != nil {
-				log.Println(err)
-			}
+			return issues.send(notice, nation.Issues)
 		default:
-			text := fmt.Sprintf("<strong>%s</strong>\n%s %s", notice.Title, notice.Who, notice.Text)
+			text := fmt.Sprintf("<strong>%s</strong>\n%s %s", html.EscapeString(notice.Title), html.EscapeString(notice.Who), html.EscapeString(notice.Text))
 			u := "https://www.nationstates.net/" + notice.URL
-			err := sendMessageWithInlineKeyboard(token, chatID, text, [][]InlineKeyboardButton{
+			return sendMessageWithInlineKeyboard(token, chatID, text, [][]InlineKeyboardButton{
 				{
 					InlineKeyboardButton{
 						Text: "View on NationStates",
@@ -244,18 +586,54 @@ func newCallback(token string, chatID int) func(notice nationstates.Notice, nati
 					},
 				},
 			})
-			if err != nil {
-				log.Println(err)
-			}
 		}
 	}
 }
 
 type Config struct {
 	Autologin string `json:"autologin"`
+	Password  string `json:"password"`
 	Token     string `json:"token"`
 	ChatID    int    `json:"chat_id"`
 	Nation    string `json:"nation"`
+	// CredentialsFile is where the autologin token and PIN issued by
+	// NationStates are saved. It defaults to credentials.json.
+	CredentialsFile string `json:"credentials_file"`
+	// APIBaseURL overrides the NationStates API endpoint, for example to run
+	// against a local fake.
+	APIBaseURL string `json:"api_base_url"`
+	// StreamURL overrides the server-sent events endpoint used to stream
+	// happenings, for example to run against a local fake.
+	StreamURL string `json:"stream_url"`
+	UserAgent string `json:"user_agent"`
+	// TelegramClientKey is the API client key used to send telegrams. No
+	// telegrams are sent if it is empty.
+	TelegramClientKey string `json:"telegram_client_key"`
+	// TelegramQueueFile is where unsent telegrams are saved. It defaults to
+	// telegrams.json.
+	TelegramQueueFile string `json:"telegram_queue_file"`
+	// Campaigns are the recruitment campaigns to run, which requires
+	// TelegramClientKey.
+	Campaigns []Campaign `json:"campaigns"`
+	// RecruitmentStateFile is where recruited nations and campaign statistics
+	// are saved. It defaults to recruitment.json.
+	RecruitmentStateFile string `json:"recruitment_state_file"`
+	// LinksFile is where the nations linked to Telegram users with /link are
+	// saved. It defaults to links.json.
+	LinksFile string `json:"links_file"`
+	// WatchedCards are the trading cards whose markets are watched.
+	WatchedCards []CardWatch `json:"watched_cards"`
+	// WatchDeck enables alerts for bids on the cards in our deck.
+	WatchDeck bool `json:"watch_deck"`
+	// CardHistoryFile is where card prices are saved. It defaults to
+	// cards.json.
+	CardHistoryFile string `json:"card_history_file"`
+	// OffsetFile is where the timestamp of the last notice sent is saved. It
+	// defaults to offset.json.
+	OffsetFile string `json:"offset_file"`
+	// ResolutionStateFile is where the resolutions which have been announced
+	// are saved. It defaults to resolutions.json.
+	ResolutionStateFile string `json:"resolution_state_file"`
 }
 
 func getConfig() (Config, error) {
@@ -271,10 +649,53 @@ func getConfig() (Config, error) {
 	return config, nil
 }
 
+func loadCredentials(path string) (nationstates.Credentials, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nationstates.Credentials{}, err
+	}
+	defer f.Close()
+	var creds nationstates.Credentials
+	err = json.NewDecoder(f).Decode(&creds)
+	if err != nil {
+		return nationstates.Credentials{}, err
+	}
+	return creds, nil
+}
+
+// credentialsMu serialises saveCredentials, which is called from the
+// credentials hook by concurrent requests.
+var credentialsMu sync.Mutex
+
+func saveCredentials(path string, creds nationstates.Credentials) error {
+	data, err := json.Marshal(creds)
+	if err != nil {
+		return err
+	}
+	credentialsMu.Lock()
+	defer credentialsMu.Unlock()
+	return atomicfile.WriteFile(path, data)
+}
+
 type Update struct {
+	Message       *Message       `json:"message"`
 	CallbackQuery *CallbackQuery `json:"callback_query"`
 }
 
+type Message struct {
+	Text string `json:"text"`
+	Chat Chat   `json:"chat"`
+	From *User  `json:"from"`
+}
+
+type User struct {
+	ID int `json:"id"`
+}
+
+type Chat struct {
+	ID int `json:"id"`
+}
+
 type CallbackQuery struct {
 	ID   string `json:"id"`
 	Data string `json:"data"`
@@ -282,17 +703,111 @@ type CallbackQuery struct {
 
 type CallbackData struct {
 	Action   string `json:"a"`
-	IssueID  int    `json:"iid"`
-	OptionID int    `json:"oid"`
+	IssueID  int    `json:"iid,omitempty"`
+	OptionID int    `json:"oid,omitempty"`
+	Council  int    `json:"c,omitempty"`
+}
+
+// formatConsequences summarises the result of answering an issue.
+func formatConsequences(conseq nationstates.Consequences) string {
+	talkingPoint := []rune(conseq.Desc)
+	if len(talkingPoint) > 0 {
+		talkingPoint[0] = unicode.ToUpper(talkingPoint[0])
+	}
+	sections := []string{fmt.Sprintf("<strong>The Talking Point</strong>\n%s.", html.EscapeString(string(talkingPoint)))}
+	if len(conseq.Reclassifications) > 0 {
+		var lines []string
+		for _, r := range conseq.Reclassifications {
+			lines = append(lines, fmt.Sprintf("%s: %s → %s", html.EscapeString(r.Label()), html.EscapeString(r.From), html.EscapeString(r.To)))
+		}
+		sections = append(sections, "<strong>Reclassifications</strong>\n"+strings.Join(lines, "\n"))
+	}
+	if len(conseq.NewPolicies) > 0 || len(conseq.RemovedPolicies) > 0 {
+		var lines []string
+		for _, policy := range conseq.NewPolicies {
+			lines = append(lines, fmt.Sprintf("✅ %s: %s", html.EscapeString(policy.Name), html.EscapeString(policy.Desc)))
+		}
+		for _, policy := range conseq.RemovedPolicies {
+			lines = append(lines, fmt.Sprintf("❌ %s", html.EscapeString(policy.Name)))
+		}
+		sections = append(sections, "<strong>Policies</strong>\n"+strings.Join(lines, "\n"))
+	}
+	if len(conseq.Unlocks) > 0 {
+		var banners []string
+		for _, banner := range conseq.Unlocks {
+			banners = append(banners, fmt.Sprintf(`<a href="%s">%s</a>`, html.EscapeString(nationstates.BannerURL(banner)), html.EscapeString(banner)))
+		}
+		sections = append(sections, fmt.Sprintf("<strong>Unlocked</strong>\n%d new banner(s): %s", len(conseq.Unlocks), strings.Join(banners, ", ")))
+	}
+	var headlines []string
+	for _, headline := range conseq.Headlines {
+		headlines = append(headlines, html.EscapeString(headline))
+	}
+	sections = append(sections, "<strong>Recent Headlines</strong>\n"+strings.Join(headlines, "\n"))
+	rankings := conseq.Rankings
+	sort.Slice(rankings, func(i, j int) bool {
+		return math.Abs(float64(rankings[i].PChange)) > math.Abs(float64(rankings[j].PChange))
+	})
+	var trends []string
+	for _, ranking := range rankings {
+		trends = append(trends, formatRanking(ranking))
+	}
+	sections = append(sections, "<strong>Recent trends</strong>\n"+strings.Join(trends, "\n"))
+	return strings.Join(sections, "\n\n")
 }
 
-func newUpdateHandler(client *nationstates.Client, nation, token string, chatID int) func(w http.ResponseWriter, r *http.Request) {
+// formatRanking describes a change in a census score, noting whether the
+// change is for the better where the scale has a preferred direction.
+func formatRanking(ranking nationstates.Rank) string {
+	info := nationstates.LookupCensus(ranking.ID)
+	var direction string
+	if ranking.PChange > 0 {
+		direction = "📈"
+	} else {
+		direction = "📉"
+	}
+	var verdict string
+	if (ranking.PChange > 0 && info.HigherIsBetter()) || (ranking.PChange < 0 && info.LowerIsBetter()) {
+		verdict = " 👍"
+	} else if (ranking.PChange < 0 && info.HigherIsBetter()) || (ranking.PChange > 0 && info.LowerIsBetter()) {
+		verdict = " 👎"
+	}
+	score := fmt.Sprintf("%.2f", ranking.Score)
+	if info.Unit != "" {
+		score += " " + info.Unit
+	}
+	return fmt.Sprintf("%s %s: %.2f%% (%s)%s", direction, info.Name, ranking.PChange, score, verdict)
+}
+
+func newUpdateHandler(client *nationstates.Client, links *Links, nation, token string, chatID int) func(w http.ResponseWriter, r *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var u Update
 		err := json.NewDecoder(r.Body).Decode(&u)
 		if err != nil {
 			return
 		}
+		if m := u.Message; m != nil && m.From != nil {
+			if name, _ := splitCommand(m.Text); name == "/link" || name == "/verify" {
+				ctx, cancel := context.WithTimeout(r.Context(), apiTimeout)
+				defer cancel()
+				err := handleLink(ctx, client, links, token, m)
+				if err != nil {
+					log.Println(err)
+				}
+				return
+			}
+		}
+		// only accept other commands from the configured chat, since they act
+		// on behalf of the nation
+		if m := u.Message; m != nil && m.Chat.ID == chatID && strings.HasPrefix(m.Text, "/") {
+			ctx, cancel := context.WithTimeout(r.Context(), apiTimeout)
+			defer cancel()
+			err := handleCommand(ctx, client, nation, token, chatID, m.Text)
+			if err != nil {
+				log.Println(err)
+			}
+			return
+		}
 		callbackQuery := u.CallbackQuery
 		if callbackQuery == nil {
 			return
@@ -304,41 +819,19 @@ func newUpdateHandler(client *nationstates.Client, nation, token string, chatID
 		}
 		switch d.Action {
 		case "answerIssue":
-			conseq, err := client.AnswerIssue(nation, d.IssueID, d.OptionID)
-			if err != nil {
+			ctx, cancel := context.WithTimeout(r.Context(), apiTimeout)
+			defer cancel()
+			conseq, err := client.AnswerIssueContext(ctx, nation, d.IssueID, d.OptionID)
+			var issueErr *nationstates.IssueError
+			if err != nil && !errors.As(err, &issueErr) {
 				log.Println(err)
 				return
 			}
 			var text string
-			if conseq.Error != "" {
-				text = conseq.Error
+			if issueErr != nil {
+				text = html.EscapeString(issueErr.Message)
 			} else {
-				talkingPoint := []rune(conseq.Desc)
-				talkingPoint[0] = unicode.ToUpper(talkingPoint[0])
-				headlines := strings.Join(conseq.Headlines, "\n")
-				rankings := conseq.Rankings
-				sort.Slice(rankings, func(i, j int) bool {
-					return math.Abs(float64(rankings[i].PChange)) > math.Abs(float64(rankings[j].PChange))
-				})
-				var trends []string
-				for _, ranking := range rankings {
-					var direction string
-					if ranking.PChange > 0 {
-						direction = "📈"
-					} else {
-						direction = "📉"
-					}
-					trends = append(trends, fmt.Sprintf("%s %s: %.2f%%", direction, nationstates.CensusLabels[ranking.ID], ranking.PChange))
-				}
-				recentTrends := strings.Join(trends, "\n")
-				text = fmt.Sprintf(`<strong>The Talking Point</strong>
-%s.
-
-<strong>Recent Headlines</strong>
-%s
-
-<strong>Recent trends</strong>
-%s`, string(talkingPoint), headlines, recentTrends)
+				text = formatConsequences(conseq)
 			}
 			err = sendMessage(token, chatID, text)
 			if err != nil {
@@ -348,6 +841,34 @@ func newUpdateHandler(client *nationstates.Client, nation, token string, chatID
 			if err != nil {
 				log.Println(err)
 			}
+		case "dismissIssue":
+			ctx, cancel := context.WithTimeout(r.Context(), apiTimeout)
+			defer cancel()
+			text := "Issue dismissed."
+			err := client.DismissIssueContext(ctx, nation, d.IssueID)
+			if err != nil {
+				log.Println(err)
+				text = fmt.Sprintf("Could not dismiss issue: %s", html.EscapeString(err.Error()))
+			}
+			err = sendMessage(token, chatID, text)
+			if err != nil {
+				log.Println(err)
+			}
+			err = answerCallbackQuery(token, callbackQuery.ID)
+			if err != nil {
+				log.Println(err)
+			}
+		case "waTally":
+			ctx, cancel := context.WithTimeout(r.Context(), apiTimeout)
+			defer cancel()
+			err := sendTally(ctx, client, token, chatID, d.Council)
+			if err != nil {
+				log.Println(err)
+			}
+			err = answerCallbackQuery(token, callbackQuery.ID)
+			if err != nil {
+				log.Println(err)
+			}
 		}
 	}
 }
@@ -357,17 +878,167 @@ func main() {
 	if err != nil {
 		log.Fatal(err)
 	}
-	client := &nationstates.Client{
-		Autologin: config.Autologin,
+	var opts []nationstates.ClientOption
+	if config.APIBaseURL != "" {
+		opts = append(opts, nationstates.WithBaseURL(config.APIBaseURL))
+	}
+	if config.StreamURL != "" {
+		opts = append(opts, nationstates.WithStreamURL(config.StreamURL))
+	}
+	if config.UserAgent != "" {
+		opts = append(opts, nationstates.WithUserAgent(config.UserAgent))
+	}
+	credentialsFile := config.CredentialsFile
+	if credentialsFile == "" {
+		credentialsFile = "credentials.json"
+	}
+	opts = append(opts, nationstates.WithCredentialsHook(func(creds nationstates.Credentials) {
+		err := saveCredentials(credentialsFile, creds)
+		if err != nil {
+			log.Println(err)
+		}
+	}))
+	initial := nationstates.Credentials{Autologin: config.Autologin}
+	creds, err := loadCredentials(credentialsFile)
+	switch {
+	case err == nil && creds.Autologin != "":
+		initial = creds
+	case err == nil:
+		log.Printf("ignoring %s because it has no autologin token", credentialsFile)
+	case !os.IsNotExist(err):
+		log.Println(err)
+	}
+	opts = append(opts, nationstates.WithPassword(config.Password), nationstates.WithCredentials(initial))
+	// the client logs in with the password on its first request, so a
+	// NationStates outage at startup does not stop the bot
+	client := nationstates.NewClient(opts...)
+	offsetFile := config.OffsetFile
+	if offsetFile == "" {
+		offsetFile = "offset.json"
+	}
+	offsetter, err := NewFileOffsetter(offsetFile, 0)
+	if err != nil {
+		log.Fatal(err)
 	}
+	triggers := make(chan struct{}, 1)
 	notifier := Notifier{
 		PollInterval:     time.Hour,
+		Timeout:          time.Minute,
 		Client:           client,
 		Nation:           config.Nation,
-		AdditionalShards: []string{"issues"},
+		AdditionalShards: []nationstates.Shard{nationstates.ShardIssues},
 		Callback:         newCallback(config.Token, config.ChatID),
-		Offsetter:        NewInMemoryOffsetter(0),
+		Offsetter:        offsetter,
+		Triggers:         triggers,
+		Alert: func(text string) {
+			err := sendMessage(config.Token, config.ChatID, text)
+			if err != nil {
+				log.Println(err)
+			}
+		},
 	}
 	go notifier.Start()
-	log.Fatal(http.ListenAndServe(":8080", http.HandlerFunc(newUpdateHandler(client, config.Nation, config.Token, config.ChatID))))
+	go func() {
+		// NationStates may take a moment to add the notice for an event, so
+		// wait briefly before polling
+		err := client.Stream(context.Background(), []string{nationstates.NationBucket(config.Nation)}, func(e nationstates.Event) {
+			time.AfterFunc(streamTriggerDelay, func() {
+				select {
+				case triggers <- struct{}{}:
+				default:
+				}
+			})
+		})
+		log.Println(err)
+	}()
+	resolutionStateFile := config.ResolutionStateFile
+	if resolutionStateFile == "" {
+		resolutionStateFile = "resolutions.json"
+	}
+	resolutionWatcher := ResolutionWatcher{
+		PollInterval: time.Hour,
+		Client:       client,
+		Councils:     []int{nationstates.GeneralAssembly, nationstates.SecurityCouncil},
+		Callback:     newResolutionCallback(config.Token, config.ChatID),
+		StateFile:    resolutionStateFile,
+	}
+	go resolutionWatcher.Start()
+	if len(config.WatchedCards) > 0 || config.WatchDeck {
+		historyFile := config.CardHistoryFile
+		if historyFile == "" {
+			historyFile = "cards.json"
+		}
+		var deckNation string
+		if config.WatchDeck {
+			deckNation = config.Nation
+		}
+		cardWatcher, err := NewCardWatcher(client, deckNation, config.WatchedCards, historyFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		cardWatcher.Alert = func(text string) {
+			err := sendMessage(config.Token, config.ChatID, text)
+			if err != nil {
+				log.Println(err)
+			}
+		}
+		go cardWatcher.Start()
+	}
+	if config.TelegramClientKey != "" {
+		queueFile := config.TelegramQueueFile
+		if queueFile == "" {
+			queueFile = "telegrams.json"
+		}
+		queue, err := nationstates.NewTelegramQueue(client, config.TelegramClientKey, queueFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		var recruiter *Recruiter
+		if len(config.Campaigns) > 0 {
+			stateFile := config.RecruitmentStateFile
+			if stateFile == "" {
+				stateFile = "recruitment.json"
+			}
+			recruiter, err = NewRecruiter(client, queue, config.Campaigns, stateFile)
+			if err != nil {
+				log.Fatal(err)
+			}
+			recruiter.Report = func(text string) {
+				err := sendMessage(config.Token, config.ChatID, text)
+				if err != nil {
+					log.Println(err)
+				}
+			}
+		}
+		queue.OnSent = func(t nationstates.QueuedTelegram) {
+			if recruiter != nil {
+				recruiter.TelegramSent(t, nil)
+			}
+		}
+		queue.OnError = func(t nationstates.QueuedTelegram, err error) {
+			log.Printf("sending telegram %s to %s: %v", t.Telegram.ID, t.To, err)
+			if recruiter != nil {
+				recruiter.TelegramSent(t, err)
+			}
+		}
+		queue.OnSaveError = func(err error) {
+			log.Printf("saving telegram queue: %v", err)
+		}
+		if recruiter != nil {
+			queue.Skip = recruiter.SkipTelegram
+			go recruiter.Start()
+		}
+		go func() {
+			log.Println(queue.Run(context.Background()))
+		}()
+	}
+	linksFile := config.LinksFile
+	if linksFile == "" {
+		linksFile = "links.json"
+	}
+	links, err := LoadLinks(linksFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Fatal(http.ListenAndServe(":8080", http.HandlerFunc(newUpdateHandler(client, links, config.Nation, config.Token, config.ChatID))))
 }
@@ -0,0 +1,140 @@
+package nationstates
+
+import (
+	"context"
+	"encoding/xml"
+	"strconv"
+)
+
+// Card rarities.
+const (
+	CardCommon    = "common"
+	CardUncommon  = "uncommon"
+	CardRare      = "rare"
+	CardUltraRare = "ultra-rare"
+	CardEpic      = "epic"
+	CardLegendary = "legendary"
+)
+
+// Market order types.
+const (
+	MarketAsk = "ask"
+	MarketBid = "bid"
+)
+
+// Cards holds the shards returned by the Cards API.
+type Cards struct {
+	XMLName     xml.Name     `xml:"CARDS"`
+	Deck        []Card       `xml:"DECK>CARD"`
+	Info        DeckInfo     `xml:"INFO"`
+	Trades      []Trade      `xml:"TRADES>TRADE"`
+	Collections []Collection `xml:"COLLECTIONS>COLLECTION"`
+	Collection  Collection   `xml:"COLLECTION"`
+	Auctions    []Card       `xml:"AUCTIONS>AUCTION"`
+}
+
+// Card is a trading card. Cards in a deck, collection or auction only have
+// their ID, season and rarity set.
+type Card struct {
+	ID          int           `xml:"CARDID"`
+	Season      int           `xml:"SEASON"`
+	Category    string        `xml:"CATEGORY"`
+	Name        string        `xml:"NAME"`
+	Flag        string        `xml:"FLAG"`
+	Govt        string        `xml:"GOVT"`
+	MarketValue float64       `xml:"MARKET_VALUE"`
+	Region      string        `xml:"REGION"`
+	Slogan      string        `xml:"SLOGAN"`
+	Type        string        `xml:"TYPE"`
+	Markets     []MarketOrder `xml:"MARKETS>MARKET"`
+	Owners      []string      `xml:"OWNERS>OWNER"`
+	Trades      []Trade       `xml:"TRADES>TRADE"`
+}
+
+// DeckInfo summarises the deck of a nation.
+type DeckInfo struct {
+	ID             int     `xml:"ID"`
+	Name           string  `xml:"NAME"`
+	Bank           float64 `xml:"BANK"`
+	DeckCapacity   int     `xml:"DECK_CAPACITY_RAW"`
+	DeckValue      float64 `xml:"DECK_VALUE"`
+	LastPackOpened int     `xml:"LAST_PACK_OPENED"`
+	LastValued     int     `xml:"LAST_VALUED"`
+	NumCards       int     `xml:"NUM_CARDS"`
+	Rank           int     `xml:"RANK"`
+	RegionRank     int     `xml:"REGION_RANK"`
+}
+
+// MarketOrder is an ask or bid for a card.
+type MarketOrder struct {
+	Nation    string  `xml:"NATION"`
+	Price     float64 `xml:"PRICE"`
+	Timestamp int     `xml:"TIMESTAMP"`
+	Type      string  `xml:"TYPE"`
+}
+
+// Trade is a completed sale of a card. Trades of a single card do not have
+// the card ID, season or rarity set. Gifts have no price.
+type Trade struct {
+	CardID    int     `xml:"CARDID"`
+	Season    int     `xml:"SEASON"`
+	Category  string  `xml:"CATEGORY"`
+	Buyer     string  `xml:"BUYER"`
+	Seller    string  `xml:"SELLER"`
+	Price     float64 `xml:"PRICE"`
+	Timestamp int     `xml:"TIMESTAMP"`
+}
+
+// Collection is a named set of cards curated by a nation.
+type Collection struct {
+	ID      int    `xml:"ID"`
+	Name    string `xml:"NAME"`
+	Updated int    `xml:"UPDATED"`
+	Cards   []Card `xml:"DECK>CARD"`
+}
+
+// GetCards is a generic method for querying the Cards API.
+func (c *Client) GetCards(shards []Shard, params ...Param) (Cards, error) {
+	return c.GetCardsContext(context.Background(), shards, params...)
+}
+
+// GetCardsContext is like GetCards but with a context.
+func (c *Client) GetCardsContext(ctx context.Context, shards []Shard, params ...Param) (Cards, error) {
+	q := query(append([]Shard{"cards"}, shards...), params)
+	var cards Cards
+	err := c.doPublic(ctx, q, &cards)
+	return cards, err
+}
+
+// GetCard is a generic method for querying a single card with the info,
+// markets, owners or trades shards.
+func (c *Client) GetCard(id, season int, shards []Shard, params ...Param) (Card, error) {
+	return c.GetCardContext(context.Background(), id, season, shards, params...)
+}
+
+// GetCardContext is like GetCard but with a context.
+func (c *Client) GetCardContext(ctx context.Context, id, season int, shards []Shard, params ...Param) (Card, error) {
+	q := query(append([]Shard{"card"}, shards...), params)
+	q.Set("cardid", strconv.Itoa(id))
+	q.Set("season", strconv.Itoa(season))
+	var card struct {
+		XMLName xml.Name `xml:"CARD"`
+		Card
+	}
+	err := c.doPublic(ctx, q, &card)
+	return card.Card, err
+}
+
+// GetDeck is a convenience method for getting the cards and deck info of a nation.
+func (c *Client) GetDeck(nation string) ([]Card, DeckInfo, error) {
+	return c.GetDeckContext(context.Background(), nation)
+}
+
+// GetDeckContext is like GetDeck but with a context.
+func (c *Client) GetDeckContext(ctx context.Context, nation string) ([]Card, DeckInfo, error) {
+	cards, err := c.GetCardsContext(ctx, []Shard{ShardDeck, ShardInfo}, NationName(nation))
+	if err != nil {
+		return nil, DeckInfo{}, err
+	}
+	return cards.Deck, cards.Info, nil
+}
@@ -0,0 +1,71 @@
+package nationstates
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestGetDeck(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if got, want := q.Get("q"), "cards+deck+info"; got != want {
+			t.Errorf("got q %q, wanted %q", got, want)
+		}
+		if got, want := q.Get("nationname"), "testlandia"; got != want {
+			t.Errorf("got nationname %q, wanted %q", got, want)
+		}
+		w.Write([]byte(`<CARDS>
+<DECK><CARD><CARDID>1</CARDID><CATEGORY>epic</CATEGORY><SEASON>2</SEASON></CARD></DECK>
+<INFO><BANK>12.5</BANK><DECK_CAPACITY_RAW>100</DECK_CAPACITY_RAW><DECK_VALUE>3.14</DECK_VALUE><ID>42</ID><LAST_PACK_OPENED>1600000000</LAST_PACK_OPENED><LAST_VALUED>1600000001</LAST_VALUED><NAME>testlandia</NAME><NUM_CARDS>1</NUM_CARDS><RANK>10</RANK><REGION_RANK>2</REGION_RANK></INFO>
+</CARDS>`))
+	}))
+	defer server.Close()
+	c := NewClient(WithBaseURL(server.URL))
+	deck, info, err := c.GetDeck("testlandia")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []Card{{ID: 1, Season: 2, Category: CardEpic}}; !reflect.DeepEqual(deck, want) {
+		t.Errorf("got deck %+v, wanted %+v", deck, want)
+	}
+	want := DeckInfo{ID: 42, Name: "testlandia", Bank: 12.5, DeckCapacity: 100, DeckValue: 3.14, LastPackOpened: 1600000000, LastValued: 1600000001, NumCards: 1, Rank: 10, RegionRank: 2}
+	if info != want {
+		t.Errorf("got info %+v, wanted %+v", info, want)
+	}
+}
+
+func TestGetCard(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("q") != "card+info+markets+owners+trades" || q.Get("cardid") != "1" || q.Get("season") != "2" {
+			t.Errorf("got query %s", r.URL.RawQuery)
+		}
+		w.Write([]byte(`<CARD id="1" season="2">
+<CARDID>1</CARDID><CATEGORY>epic</CATEGORY><MARKET_VALUE>5.00</MARKET_VALUE><NAME>Testlandia</NAME><SEASON>2</SEASON>
+<MARKETS><MARKET><NATION>buyer</NATION><PRICE>4.50</PRICE><TIMESTAMP>1600000000</TIMESTAMP><TYPE>bid</TYPE></MARKET></MARKETS>
+<OWNERS><OWNER>testlandia</OWNER><OWNER>testlandia</OWNER></OWNERS>
+<TRADES><TRADE><BUYER>testlandia</BUYER><PRICE></PRICE><SELLER>other</SELLER><TIMESTAMP>1500000000</TIMESTAMP></TRADE></TRADES>
+</CARD>`))
+	}))
+	defer server.Close()
+	c := NewClient(WithBaseURL(server.URL))
+	card, err := c.GetCard(1, 2, []Shard{ShardInfo, ShardMarkets, ShardOwners, ShardTrades})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Card{
+		ID:          1,
+		Season:      2,
+		Category:    CardEpic,
+		Name:        "Testlandia",
+		MarketValue: 5,
+		Markets:     []MarketOrder{{Nation: "buyer", Price: 4.5, Timestamp: 1600000000, Type: MarketBid}},
+		Owners:      []string{"testlandia", "testlandia"},
+		Trades:      []Trade{{Buyer: "testlandia", Seller: "other", Timestamp: 1500000000}},
+	}
+	if !reflect.DeepEqual(card, want) {
+		t.Fatalf("got %+v, wanted %+v", card, want)
+	}
+}
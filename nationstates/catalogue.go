@@ -0,0 +1,88 @@
+package nationstates
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+)
+
+//go:generate go run ./internal/gencensus -o census.json
+
+// censusJSON is the census catalogue. The checked-in catalogue was written by
+// hand, so its high and low descriptions paraphrase the scales rather than
+// quote NationStates. Running go generate replaces the names, titles, units
+// and high descriptions with those from the censusname, censustitle,
+// censusscale and censusdesc world shards, while the category, direction and
+// low descriptions, which NationStates does not provide, are kept.
+//
+//go:embed census.json
+var censusJSON []byte
+
+// CensusInfo describes a census scale.
+type CensusInfo struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Title string `json:"title"`
+	// Unit is the unit scores on the scale are measured in.
+	Unit     string `json:"unit"`
+	Category string `json:"category"`
+	// Better is "higher" or "lower" if a higher or lower score is generally
+	// desirable, and empty if the scale is a matter of taste. The sizes of
+	// individual industries and sectors are left empty, since which ones a
+	// nation wants depends on its policies.
+	Better string `json:"better"`
+	// High describes nations which score highly on the scale and Low
+	// describes nations which score poorly.
+	High string `json:"high"`
+	Low  string `json:"low"`
+}
+
+// HigherIsBetter reports whether a higher score on the scale is desirable.
+func (i CensusInfo) HigherIsBetter() bool {
+	return i.Better == "higher"
+}
+
+// LowerIsBetter reports whether a lower score on the scale is desirable.
+func (i CensusInfo) LowerIsBetter() bool {
+	return i.Better == "lower"
+}
+
+// CensusCatalogue holds every known census scale by ID.
+var CensusCatalogue = loadCensusCatalogue(censusJSON)
+
+func loadCensusCatalogue(data []byte) map[int]CensusInfo {
+	var scales []CensusInfo
+	err := json.Unmarshal(data, &scales)
+	if err != nil {
+		panic(fmt.Sprintf("nationstates: invalid census catalogue: %v", err))
+	}
+	catalogue := make(map[int]CensusInfo, len(scales))
+	for _, scale := range scales {
+		catalogue[scale.ID] = scale
+	}
+	return catalogue
+}
+
+// CensusLabels maps census scale IDs to their names.
+//
+// Deprecated: Use LookupCensus or CensusCatalogue, which also have the title,
+// unit and description of each scale.
+var CensusLabels = censusLabels(CensusCatalogue)
+
+func censusLabels(catalogue map[int]CensusInfo) map[int]string {
+	labels := make(map[int]string, len(catalogue)+1)
+	for id, info := range catalogue {
+		labels[id] = info.Name
+	}
+	labels[CensusAlphabetical] = "Alphabetical"
+	return labels
+}
+
+// LookupCensus returns information about the census scale with the given ID.
+// Unknown scales are given a placeholder name.
+func LookupCensus(id int) CensusInfo {
+	if info, ok := CensusCatalogue[id]; ok {
+		return info
+	}
+	return CensusInfo{ID: id, Name: fmt.Sprintf("Census scale %d", id)}
+}
@@ -0,0 +1,39 @@
+package nationstates
+
+import (
+	"testing"
+)
+
+func TestCensusCatalogue(t *testing.T) {
+	for id := CensusCivilRights; id <= CensusFoodQuality; id++ {
+		info, ok := CensusCatalogue[id]
+		if !ok {
+			t.Errorf("scale %d: missing from catalogue", id)
+			continue
+		}
+		if info.Name == "" || info.Unit == "" || info.Category == "" {
+			t.Errorf("scale %d: got %+v, wanted name, unit and category", id, info)
+		}
+		if info.Better != "" && info.Better != "higher" && info.Better != "lower" {
+			t.Errorf("scale %d: got better %q, wanted higher, lower or empty", id, info.Better)
+		}
+	}
+	if got, want := LookupCensus(CensusSectorManufacturing).Name, "Sector: Manufacturing"; got != want {
+		t.Errorf("got name %q, wanted %q", got, want)
+	}
+	if info := LookupCensus(CensusIndustryArmsManufacturing); info.HigherIsBetter() || info.LowerIsBetter() {
+		t.Errorf("got better %q for an industry, wanted empty", info.Better)
+	}
+	if got, want := LookupCensus(1000).Name, "Census scale 1000"; got != want {
+		t.Errorf("got name %q, wanted %q", got, want)
+	}
+}
+
+func TestCensusLabels(t *testing.T) {
+	if got, want := CensusLabels[CensusCivilRights], "Civil Rights"; got != want {
+		t.Errorf("got label %q, wanted %q", got, want)
+	}
+	if got, want := CensusLabels[CensusAlphabetical], "Alphabetical"; got != want {
+		t.Errorf("got label %q, wanted %q", got, want)
+	}
+}
@@ -0,0 +1,81 @@
+package nationstates
+
+import (
+	"context"
+)
+
+// CensusScale is a nation or region's standing on a census scale. Which
+// fields are filled in depends on the census modes requested.
+type CensusScale struct {
+	ID    int     `xml:"id,attr"`
+	Score float64 `xml:"SCORE"`
+	// Rank is the world rank.
+	Rank int `xml:"RANK"`
+	// RRank is the rank within the region.
+	RRank int `xml:"RRANK"`
+	// PRank is the world rank as a percentage, where 1 is the top 1%.
+	PRank float64 `xml:"PRANK"`
+	// PRRank is the rank within the region as a percentage.
+	PRRank float64 `xml:"PRRANK"`
+	// Points are past scores, returned with CensusModeHistory.
+	Points []CensusPoint `xml:"POINT"`
+}
+
+// CensusPoint is a census score at a point in time.
+type CensusPoint struct {
+	Timestamp int     `xml:"TIMESTAMP"`
+	Score     float64 `xml:"SCORE"`
+}
+
+// FindCensusScale returns the scale with the given ID from scales.
+func FindCensusScale(scales []CensusScale, id int) (CensusScale, bool) {
+	for _, scale := range scales {
+		if scale.ID == id {
+			return scale, true
+		}
+	}
+	return CensusScale{}, false
+}
+
+// GetCensus is a convenience method for getting a nation's current scores
+// and ranks on the given census scales. If no modes are given, NationStates
+// returns the score, world rank and region rank.
+func (c *Client) GetCensus(nation string, scales CensusScales, modes ...CensusMode) ([]CensusScale, error) {
+	return c.GetCensusContext(context.Background(), nation, scales, modes...)
+}
+
+// GetCensusContext is like GetCensus but with a context.
+func (c *Client) GetCensusContext(ctx context.Context, nation string, scales CensusScales, modes ...CensusMode) ([]CensusScale, error) {
+	params := []Param{scales}
+	if len(modes) > 0 {
+		params = append(params, CensusModes(modes))
+	}
+	n, err := c.GetPublicNationContext(ctx, nation, []Shard{ShardCensus}, params...)
+	if err != nil {
+		return nil, err
+	}
+	return n.Census, nil
+}
+
+// GetCensusHistory is a convenience method for getting a nation's past scores
+// on the given census scales between from and to. A zero from or to leaves
+// that end of the range open.
+func (c *Client) GetCensusHistory(nation string, scales CensusScales, from, to int) ([]CensusScale, error) {
+	return c.GetCensusHistoryContext(context.Background(), nation, scales, from, to)
+}
+
+// GetCensusHistoryContext is like GetCensusHistory but with a context.
+func (c *Client) GetCensusHistoryContext(ctx context.Context, nation string, scales CensusScales, from, to int) ([]CensusScale, error) {
+	params := []Param{scales, CensusModeHistory}
+	if from > 0 {
+		params = append(params, From(from))
+	}
+	if to > 0 {
+		params = append(params, To(to))
+	}
+	n, err := c.GetPublicNationContext(ctx, nation, []Shard{ShardCensus}, params...)
+	if err != nil {
+		return nil, err
+	}
+	return n.Census, nil
+}
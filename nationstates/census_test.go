@@ -0,0 +1,88 @@
+package nationstates
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestUnmarshalCensus(t *testing.T) {
+	s := `<NATION id="testlandia">
+  <CENSUS>
+    <SCALE id="0">
+      <SCORE>67.33</SCORE>
+      <RANK>41234</RANK>
+      <RRANK>12</RRANK>
+      <PRANK>16</PRANK>
+      <PRRANK>44</PRRANK>
+    </SCALE>
+    <SCALE id="1">
+      <SCORE>80</SCORE>
+      <RANK>20001</RANK>
+      <RRANK>3</RRANK>
+      <PRANK>8</PRANK>
+      <PRRANK>11</PRRANK>
+    </SCALE>
+  </CENSUS>
+</NATION>
+`
+	var n Nation
+	err := xml.Unmarshal([]byte(s), &n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []CensusScale{
+		{ID: CensusCivilRights, Score: 67.33, Rank: 41234, RRank: 12, PRank: 16, PRRank: 44},
+		{ID: CensusEconomy, Score: 80, Rank: 20001, RRank: 3, PRank: 8, PRRank: 11},
+	}
+	if !reflect.DeepEqual(n.Census, want) {
+		t.Fatalf("got %+v, wanted %+v", n.Census, want)
+	}
+	if scale, ok := FindCensusScale(n.Census, CensusEconomy); !ok || scale.Score != 80 {
+		t.Fatalf("got %+v, %t, wanted economy scale", scale, ok)
+	}
+}
+
+func TestGetCensusHistory(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		w.Write([]byte(`<NATION id="testlandia">
+  <CENSUS>
+    <SCALE id="46">
+      <POINT>
+        <TIMESTAMP>1581638400</TIMESTAMP>
+        <SCORE>2511.1</SCORE>
+      </POINT>
+      <POINT>
+        <TIMESTAMP>1581724800</TIMESTAMP>
+        <SCORE>2514.2</SCORE>
+      </POINT>
+    </SCALE>
+  </CENSUS>
+</NATION>`))
+	}))
+	defer server.Close()
+	c := NewClient(WithBaseURL(server.URL))
+	scales, err := c.GetCensusHistory("testlandia", CensusScales{CensusDefenseForces}, 1581638400, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "from=1581638400&mode=history&nation=testlandia&q=census&scale=46"; query != want {
+		t.Errorf("got query %q, wanted %q", query, want)
+	}
+	want := []CensusScale{
+		{
+			ID: CensusDefenseForces,
+			Points: []CensusPoint{
+				{Timestamp: 1581638400, Score: 2511.1},
+				{Timestamp: 1581724800, Score: 2514.2},
+			},
+		},
+	}
+	if !reflect.DeepEqual(scales, want) {
+		t.Fatalf("got %+v, wanted %+v", scales, want)
+	}
+}
@@ -1,81 +1,270 @@
 package nationstates
 
 import (
+	"context"
 	"encoding/xml"
-	"errors"
-	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
+	"sync"
 )
 
+const (
+	// DefaultBaseURL is the NationStates API endpoint.
+	DefaultBaseURL = "https://www.nationstates.net/cgi-bin/api.cgi"
+	// DefaultUserAgent is sent with every request unless overridden with WithUserAgent.
+	DefaultUserAgent = "NationStates Go client"
+	// DefaultStreamURL is the NationStates server-sent events endpoint.
+	DefaultStreamURL = "https://www.nationstates.net/api/"
+)
+
+// Client is a NationStates API client. It is safe for concurrent use by
+// multiple goroutines. The password and initial credentials are set with
+// WithPassword and WithCredentials, and Credentials returns the autologin
+// token and PIN once the client is in use.
 type Client struct {
+	// Password, Autologin and Pin are read once, before the first request.
+	// After that Autologin and Pin mirror the current credentials, but they
+	// are updated under a lock, so they should be read with Credentials.
+	//
+	// Deprecated: Use WithPassword and WithCredentials to set the
+	// credentials, and Credentials to read them.
 	Password  string
 	Autologin string
 	Pin       string
 
-	client *http.Client
+	password  string
+	autologin string
+	pin       string
+
+	baseURL   string
+	streamURL string
+	userAgent string
+	client    *http.Client
+
+	onCredentials func(Credentials)
+
+	// mu guards autologin and pin, which are refreshed from responses, and
+	// their deprecated exported mirrors.
+	mu sync.RWMutex
+	// fieldsOnce copies the deprecated exported credentials into the client
+	// before they are first used.
+	fieldsOnce sync.Once
+	// loginMu ensures only one request without a PIN is in flight at a time,
+	// so that concurrent requests do not each create a new login session.
+	loginMu sync.Mutex
+
+	limiterOnce sync.Once
+	limiter     *rateLimiter
 }
 
-func (c *Client) do(options map[string]interface{}) (Nation, error) {
-	req, err := http.NewRequest(http.MethodGet, "https://www.nationstates.net/cgi-bin/api.cgi", nil)
-	if err != nil {
-		return Nation{}, err
+// ClientOption configures a Client created with NewClient.
+type ClientOption func(c *Client)
+
+// WithBaseURL sets the API endpoint requests are made to, for example to point
+// the client at a local stand-in for NationStates.
+func WithBaseURL(baseURL string) ClientOption {
+	return func(c *Client) {
+		c.baseURL = baseURL
+	}
+}
+
+// WithStreamURL sets the server-sent events endpoint used by Stream.
+func WithStreamURL(streamURL string) ClientOption {
+	return func(c *Client) {
+		c.streamURL = streamURL
+	}
+}
+
+// WithHTTPClient sets the HTTP client used to make requests.
+func WithHTTPClient(client *http.Client) ClientOption {
+	return func(c *Client) {
+		c.client = client
+	}
+}
+
+// WithTransport sets the RoundTripper used to make requests.
+func WithTransport(transport http.RoundTripper) ClientOption {
+	return func(c *Client) {
+		c.client = &http.Client{Transport: transport}
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request. NationStates
+// asks that it identify the operator of the script.
+func WithUserAgent(userAgent string) ClientOption {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+// NewClient returns a Client configured with opts. The zero Client is also
+// usable and talks to NationStates with http.DefaultClient.
+func NewClient(opts ...ClientOption) *Client {
+	c := new(Client)
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// rateLimiter returns the rate limiter shared by all requests made with c.
+func (c *Client) rateLimiter() *rateLimiter {
+	c.limiterOnce.Do(func() {
+		c.limiter = newRateLimiter(RateLimitRequests, RateLimitWindow)
+	})
+	return c.limiter
+}
+
+// do makes a GET request to the API, logging in again once if NationStates
+// rejects the current login session.
+func (c *Client) do(ctx context.Context, params url.Values, v interface{}) error {
+	creds, err := c.doAuthenticated(ctx, http.MethodGet, params, v)
+	if err != nil && c.expire(creds, err) {
+		_, err = c.doAuthenticated(ctx, http.MethodGet, params, v)
 	}
-	req.Header.Set("User-Agent", "NationStates Go client")
-	if password := c.Password; password != "" {
-		req.Header.Set("X-Password", password)
+	return err
+}
+
+// doAuthenticated makes a request with the current credentials and returns
+// the credentials which were used.
+func (c *Client) doAuthenticated(ctx context.Context, method string, params url.Values, v interface{}) (Credentials, error) {
+	creds := c.Credentials()
+	if creds.Pin == "" && c.canLogin() {
+		c.loginMu.Lock()
+		defer c.loginMu.Unlock()
+		// another request may have logged in while we were waiting
+		creds = c.Credentials()
 	}
-	if autologin := c.Autologin; autologin != "" {
-		req.Header.Set("X-Autologin", autologin)
+	err := c.doOnce(ctx, method, params, &creds, v)
+	return creds, err
+}
+
+// doPublic makes a GET request without the nation's credentials, for public
+// data and for APIs such as sendTG and verify which do not need a login. It
+// does not wait for a login in progress, and an error does not discard the
+// credentials.
+func (c *Client) doPublic(ctx context.Context, params url.Values, v interface{}) error {
+	return c.doOnce(ctx, http.MethodGet, params, nil, v)
+}
+
+// doOnce makes a single request with creds and decodes the response into v.
+// If creds is nil, the request is made without credentials.
+func (c *Client) doOnce(ctx context.Context, method string, params url.Values, creds *Credentials, v interface{}) error {
+	baseURL := DefaultBaseURL
+	if c.baseURL != "" {
+		baseURL = c.baseURL
 	}
-	if pin := c.Pin; pin != "" {
-		req.Header.Set("X-Pin", pin)
+	var reqBody io.Reader
+	if method == http.MethodPost {
+		reqBody = strings.NewReader(params.Encode())
 	}
-	params := url.Values{}
-	for k, v := range options {
-		params.Set(k, fmt.Sprint(v))
+	req, err := http.NewRequestWithContext(ctx, method, baseURL, reqBody)
+	if err != nil {
+		return err
+	}
+	if method == http.MethodPost {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	} else {
+		req.URL.RawQuery = params.Encode()
+	}
+	userAgent := DefaultUserAgent
+	if c.userAgent != "" {
+		userAgent = c.userAgent
+	}
+	req.Header.Set("User-Agent", userAgent)
+	if creds != nil {
+		c.setHeaders(req, *creds)
 	}
-	req.URL.RawQuery = params.Encode()
 	client := http.DefaultClient
 	if c.client != nil {
 		client = c.client
 	}
-	res, err := client.Do(req)
+	limiter := c.rateLimiter()
+	err = limiter.wait(ctx)
 	if err != nil {
-		return Nation{}, err
+		return err
 	}
-	if pin := res.Header.Get("X-Pin"); pin != "" {
-		c.Pin = pin
+	res, err := client.Do(req)
+	if err != nil {
+		return err
 	}
 	defer res.Body.Close()
+	limiter.update(res.Header)
+	if creds != nil {
+		c.updateCredentials(res.Header)
+	}
 	body, err := ioutil.ReadAll(res.Body)
 	if err != nil {
-		return Nation{}, err
+		return err
 	}
-	var n Nation
-	err = xml.Unmarshal(body, &n)
+	if res.StatusCode != http.StatusOK {
+		apiErr := &APIError{
+			StatusCode: res.StatusCode,
+			Message:    errorMessage(body),
+			RetryAfter: parseRetryAfter(res.Header),
+		}
+		if res.StatusCode == http.StatusTooManyRequests && apiErr.RetryAfter == 0 {
+			apiErr.RetryAfter = RateLimitWindow
+			limiter.pause(apiErr.RetryAfter)
+		}
+		return apiErr
+	}
+	// a few APIs such as sendTG respond with plain text instead of XML
+	if raw, ok := v.(*[]byte); ok {
+		*raw = body
+		return nil
+	}
+	err = xml.Unmarshal(body, v)
 	if err != nil {
-		return Nation{}, errors.New(string(body))
+		return &DecodeError{Message: errorMessage(body), Err: err}
 	}
-	return n, nil
+	return nil
 }
 
-// GetNation is a generic method for querying the Nation API.
-func (c *Client) GetNation(nation string, shards []string, options map[string]interface{}) (Nation, error) {
-	opts := make(map[string]interface{})
-	for k, v := range options {
-		opts[k] = v
-	}
-	opts["nation"] = nation
-	opts["q"] = strings.Join(shards, "+")
-	return c.do(opts)
+// GetNation is a generic method for querying the Nation API. The request is
+// made with the client's credentials so that private shards of our own
+// nation can be requested. Use GetPublicNation for other nations.
+func (c *Client) GetNation(nation string, shards []Shard, params ...Param) (Nation, error) {
+	return c.GetNationContext(context.Background(), nation, shards, params...)
+}
+
+// GetNationContext is like GetNation but with a context.
+func (c *Client) GetNationContext(ctx context.Context, nation string, shards []Shard, params ...Param) (Nation, error) {
+	q := query(shards, params)
+	q.Set("nation", nation)
+	var n Nation
+	err := c.do(ctx, q, &n)
+	return n, err
+}
+
+// GetPublicNation is like GetNation but makes the request without the
+// client's credentials, so it can only request public shards. A nation which
+// does not exist does not affect the client's login session.
+func (c *Client) GetPublicNation(nation string, shards []Shard, params ...Param) (Nation, error) {
+	return c.GetPublicNationContext(context.Background(), nation, shards, params...)
+}
+
+// GetPublicNationContext is like GetPublicNation but with a context.
+func (c *Client) GetPublicNationContext(ctx context.Context, nation string, shards []Shard, params ...Param) (Nation, error) {
+	q := query(shards, params)
+	q.Set("nation", nation)
+	var n Nation
+	err := c.doPublic(ctx, q, &n)
+	return n, err
 }
 
 // GetIssues is a convenience method for getting issues for a nation.
 func (c *Client) GetIssues(nation string) ([]Issue, error) {
-	n, err := c.GetNation(nation, []string{"issues"}, nil)
+	return c.GetIssuesContext(context.Background(), nation)
+}
+
+// GetIssuesContext is like GetIssues but with a context.
+func (c *Client) GetIssuesContext(ctx context.Context, nation string) ([]Issue, error) {
+	n, err := c.GetNationContext(ctx, nation, []Shard{ShardIssues})
 	if err != nil {
 		return nil, err
 	}
@@ -84,7 +273,12 @@ func (c *Client) GetIssues(nation string) ([]Issue, error) {
 
 // GetNotices is a convenience method for getting notices for a nation.
 func (c *Client) GetNotices(nation string) ([]Notice, error) {
-	n, err := c.GetNation(nation, []string{"notices"}, nil)
+	return c.GetNoticesContext(context.Background(), nation)
+}
+
+// GetNoticesContext is like GetNotices but with a context.
+func (c *Client) GetNoticesContext(ctx context.Context, nation string) ([]Notice, error) {
+	n, err := c.GetNationContext(ctx, nation, []Shard{ShardNotices})
 	if err != nil {
 		return nil, err
 	}
@@ -93,22 +287,52 @@ func (c *Client) GetNotices(nation string) ([]Notice, error) {
 
 // GetNoticesSince is a convenience method for getting notices for a nation since a given offset.
 func (c *Client) GetNoticesSince(nation string, from int) ([]Notice, error) {
-	n, err := c.GetNation(nation, []string{"notices"}, map[string]interface{}{"from": from})
+	return c.GetNoticesSinceContext(context.Background(), nation, from)
+}
+
+// GetNoticesSinceContext is like GetNoticesSince but with a context.
+func (c *Client) GetNoticesSinceContext(ctx context.Context, nation string, from int) ([]Notice, error) {
+	n, err := c.GetNationContext(ctx, nation, []Shard{ShardNotices}, From(from))
 	if err != nil {
 		return nil, err
 	}
 	return n.Notices, nil
 }
 
+// DismissOption is the option ID used to dismiss an issue without answering it.
+const DismissOption = -1
+
+// AnswerIssue answers an issue with the given option. If NationStates refuses
+// to answer the issue, the error is an *IssueError.
 func (c *Client) AnswerIssue(nation string, issue, option int) (Consequences, error) {
-	n, err := c.do(map[string]interface{}{
-		"nation": nation,
-		"c":      "issue",
-		"issue":  issue,
-		"option": option,
-	})
+	return c.AnswerIssueContext(context.Background(), nation, issue, option)
+}
+
+// AnswerIssueContext is like AnswerIssue but with a context.
+func (c *Client) AnswerIssueContext(ctx context.Context, nation string, issue, option int) (Consequences, error) {
+	var n Nation
+	err := c.do(ctx, url.Values{
+		"nation": {nation},
+		"c":      {"issue"},
+		"issue":  {strconv.Itoa(issue)},
+		"option": {strconv.Itoa(option)},
+	}, &n)
 	if err != nil {
 		return Consequences{}, err
 	}
+	if msg := n.Consequences.Error; msg != "" {
+		return n.Consequences, &IssueError{IssueID: issue, OptionID: option, Message: msg}
+	}
 	return n.Consequences, nil
 }
+
+// DismissIssue dismisses an issue without answering it.
+func (c *Client) DismissIssue(nation string, issue int) error {
+	return c.DismissIssueContext(context.Background(), nation, issue)
+}
+
+// DismissIssueContext is like DismissIssue but with a context.
+func (c *Client) DismissIssueContext(ctx context.Context, nation string, issue int) error {
+	_, err := c.AnswerIssueContext(ctx, nation, issue, DismissOption)
+	return err
+}
@@ -0,0 +1,195 @@
+package nationstates
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClientConcurrentUse(t *testing.T) {
+	var logins int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Pin") == "" {
+			atomic.AddInt32(&logins, 1)
+			w.Header().Set("X-Pin", "12345")
+		} else if pin := r.Header.Get("X-Pin"); pin != "12345" {
+			t.Errorf("got pin %q, wanted %q", pin, "12345")
+		}
+		switch r.URL.Query().Get("c") {
+		case "issue":
+			w.Write([]byte(`<NATION id="testlandia"><ISSUE id="1" choice="1"><OK>1</OK><DESC>things happened</DESC></ISSUE></NATION>`))
+		default:
+			w.Write([]byte(`<NATION id="testlandia"><NOTICES><NOTICE><TIMESTAMP>1</TIMESTAMP><TYPE>I</TYPE></NOTICE></NOTICES></NATION>`))
+		}
+	}))
+	defer server.Close()
+	c := NewClient(WithBaseURL(server.URL), WithCredentials(Credentials{Autologin: "autologin"}))
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			notices, err := c.GetNoticesSince("testlandia", 0)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if len(notices) != 1 {
+				t.Errorf("got %d notices, wanted 1", len(notices))
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			conseq, err := c.AnswerIssue("testlandia", 1, 1)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if conseq.Desc != "things happened" {
+				t.Errorf("got desc %q, wanted %q", conseq.Desc, "things happened")
+			}
+		}()
+	}
+	wg.Wait()
+	if n := atomic.LoadInt32(&logins); n != 1 {
+		t.Fatalf("got %d logins, wanted 1", n)
+	}
+}
+
+func TestClientExpiredPin(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+	}))
+	defer server.Close()
+	c := NewClient(WithBaseURL(server.URL), WithCredentials(Credentials{Autologin: "autologin", Pin: "12345"}))
+	c.GetNotices("testlandia")
+	if pin := c.Credentials().Pin; pin != "" {
+		t.Fatalf("got pin %q after 409, wanted it to be cleared", pin)
+	}
+}
+
+type recordingTransport struct {
+	requests []*http.Request
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.requests = append(t.requests, req)
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     make(http.Header),
+		Body:       ioutil.NopCloser(strings.NewReader(`<NATION id="testlandia"></NATION>`)),
+	}, nil
+}
+
+func TestClientOptions(t *testing.T) {
+	transport := new(recordingTransport)
+	c := NewClient(
+		WithBaseURL("http://localhost/api.cgi"),
+		WithTransport(transport),
+		WithUserAgent("Secretary of Testlandia"),
+	)
+	_, err := c.GetNotices("testlandia")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(transport.requests) != 1 {
+		t.Fatalf("got %d requests, wanted 1", len(transport.requests))
+	}
+	req := transport.requests[0]
+	if got, want := req.URL.Host+req.URL.Path, "localhost/api.cgi"; got != want {
+		t.Errorf("got url %q, wanted %q", got, want)
+	}
+	if got, want := req.Header.Get("User-Agent"), "Secretary of Testlandia"; got != want {
+		t.Errorf("got user agent %q, wanted %q", got, want)
+	}
+}
+
+func TestClientContext(t *testing.T) {
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-done
+	}))
+	defer server.Close()
+	defer close(done)
+	c := NewClient(WithBaseURL(server.URL))
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := c.GetNoticesContext(ctx, "testlandia")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got error %v, wanted %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestDismissIssue(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		w.Write([]byte(`<NATION id="testlandia"><ISSUE id="1234" choice="-1"><OK>1</OK></ISSUE></NATION>`))
+	}))
+	defer server.Close()
+	c := NewClient(WithBaseURL(server.URL))
+	err := c.DismissIssue("testlandia", 1234)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "c=issue&issue=1234&nation=testlandia&option=-1"; query != want {
+		t.Fatalf("got query %q, wanted %q", query, want)
+	}
+}
+
+func TestPublicRequestsWithoutCredentials(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Password") != "" || r.Header.Get("X-Autologin") != "" || r.Header.Get("X-Pin") != "" {
+			t.Errorf("got credentials in headers %v for %s, wanted none", r.Header, r.URL.RawQuery)
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+	creds := Credentials{Autologin: "autologin", Pin: "12345"}
+	c := NewClient(WithBaseURL(server.URL), WithPassword("hunter2"), WithCredentials(creds))
+	requests := map[string]func() error{
+		"nation": func() error {
+			_, err := c.GetPublicNation("other", []Shard{ShardRegion})
+			return err
+		},
+		"census": func() error {
+			_, err := c.GetCensus("other", CensusScales{0})
+			return err
+		},
+		"region": func() error {
+			_, err := c.GetRegion("the_pacific", []Shard{ShardNumNations})
+			return err
+		},
+		"world": func() error {
+			_, err := c.GetWorld([]Shard{ShardNumNations})
+			return err
+		},
+		"wa": func() error {
+			_, err := c.GetWorldAssembly(1, []Shard{ShardNumNations})
+			return err
+		},
+		"card": func() error {
+			_, err := c.GetCard(1, 1, []Shard{ShardInfo})
+			return err
+		},
+		"deck": func() error {
+			_, _, err := c.GetDeck("other")
+			return err
+		},
+	}
+	for name, request := range requests {
+		if err := request(); !errors.Is(err, ErrNotFound) {
+			t.Errorf("got error %v for %s, wanted ErrNotFound", err, name)
+		}
+	}
+	if got := c.Credentials(); got != creds {
+		t.Fatalf("got credentials %+v after public requests, wanted them kept as %+v", got, creds)
+	}
+}
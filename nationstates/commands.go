@@ -0,0 +1,214 @@
+package nationstates
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// Command is a private command which must be prepared and then executed with
+// the token returned by NationStates. It can only be implemented by the types
+// in this package.
+type Command interface {
+	// name returns the value of the c parameter for the command.
+	name() string
+	// params returns the parameters of the command, or an error if the
+	// command is invalid and would be refused by NationStates.
+	params() (url.Values, error)
+}
+
+const (
+	DispatchAdd    = "add"
+	DispatchEdit   = "edit"
+	DispatchRemove = "remove"
+)
+
+// Dispatch categories and subcategories.
+const (
+	DispatchFactbook              = 1
+	DispatchFactbookOverview      = 100
+	DispatchFactbookHistory       = 101
+	DispatchFactbookGeography     = 102
+	DispatchFactbookCulture       = 103
+	DispatchFactbookPolitics      = 104
+	DispatchFactbookLegislation   = 105
+	DispatchFactbookReligion      = 106
+	DispatchFactbookMilitary      = 107
+	DispatchFactbookEconomy       = 108
+	DispatchFactbookInternational = 109
+	DispatchFactbookTrivia        = 110
+	DispatchFactbookMiscellaneous = 111
+	DispatchBulletin              = 3
+	DispatchBulletinPolicy        = 305
+	DispatchBulletinNews          = 315
+	DispatchBulletinOpinion       = 325
+	DispatchBulletinCampaign      = 385
+	DispatchAccount               = 5
+	DispatchAccountMilitary       = 505
+	DispatchAccountTrade          = 515
+	DispatchAccountSport          = 525
+	DispatchAccountDrama          = 535
+	DispatchAccountDiplomacy      = 545
+	DispatchAccountScience        = 555
+	DispatchAccountCulture        = 565
+	DispatchAccountOther          = 595
+	DispatchMeta                  = 8
+	DispatchMetaGameplay          = 835
+	DispatchMetaReference         = 845
+)
+
+// DispatchCommand adds, edits or removes a dispatch. ID is required to edit
+// or remove a dispatch, while Title, Text, Category and Subcategory are
+// required to add or edit one.
+type DispatchCommand struct {
+	Action      string
+	ID          int
+	Title       string
+	Text        string
+	Category    int
+	Subcategory int
+}
+
+func (DispatchCommand) name() string {
+	return "dispatch"
+}
+
+func (d DispatchCommand) params() (url.Values, error) {
+	switch d.Action {
+	case DispatchAdd:
+	case DispatchEdit, DispatchRemove:
+		if d.ID == 0 {
+			return nil, fmt.Errorf("nationstates: dispatch id is required to %s a dispatch", d.Action)
+		}
+	default:
+		return nil, fmt.Errorf("nationstates: invalid dispatch action %q", d.Action)
+	}
+	params := url.Values{"dispatch": {d.Action}}
+	if d.Action != DispatchAdd {
+		params.Set("dispatchid", strconv.Itoa(d.ID))
+	}
+	if d.Action != DispatchRemove {
+		params.Set("title", d.Title)
+		params.Set("text", d.Text)
+		params.Set("category", strconv.Itoa(d.Category))
+		params.Set("subcategory", strconv.Itoa(d.Subcategory))
+	}
+	return params, nil
+}
+
+// RMBPostCommand posts a message to a Regional Message Board.
+type RMBPostCommand struct {
+	Region string
+	Text   string
+}
+
+func (RMBPostCommand) name() string {
+	return "rmbpost"
+}
+
+func (p RMBPostCommand) params() (url.Values, error) {
+	return url.Values{
+		"region": {p.Region},
+		"text":   {p.Text},
+	}, nil
+}
+
+// GiftCardCommand gifts a trading card to another nation.
+type GiftCardCommand struct {
+	CardID int
+	Season int
+	To     string
+}
+
+func (GiftCardCommand) name() string {
+	return "giftcard"
+}
+
+func (g GiftCardCommand) params() (url.Values, error) {
+	return url.Values{
+		"cardid": {strconv.Itoa(g.CardID)},
+		"season": {strconv.Itoa(g.Season)},
+		"to":     {g.To},
+	}, nil
+}
+
+// CommandError is returned when NationStates refuses to prepare or execute a
+// private command.
+type CommandError struct {
+	Command string
+	Mode    string
+	Message string
+}
+
+func (e *CommandError) Error() string {
+	return fmt.Sprintf("nationstates: cannot %s %s command: %s", e.Mode, e.Command, e.Message)
+}
+
+type commandResult struct {
+	XMLName xml.Name `xml:"NATION"`
+	Success string   `xml:"SUCCESS"`
+	Error   string   `xml:"ERROR"`
+}
+
+// Execute prepares and executes a private command for nation, and returns
+// the success message from NationStates.
+func (c *Client) Execute(nation string, cmd Command) (string, error) {
+	return c.ExecuteContext(context.Background(), nation, cmd)
+}
+
+// ExecuteContext is like Execute but with a context.
+func (c *Client) ExecuteContext(ctx context.Context, nation string, cmd Command) (string, error) {
+	params, err := cmd.params()
+	if err != nil {
+		return "", err
+	}
+	msg, creds, err := c.execute(ctx, nation, cmd, params)
+	// a token cannot be executed twice, so when the credentials are rejected
+	// the command is prepared again rather than retrying the failed step
+	if err != nil && c.expire(creds, err) {
+		msg, _, err = c.execute(ctx, nation, cmd, params)
+	}
+	return msg, err
+}
+
+// execute prepares and executes cmd once, and returns the credentials used
+// by the step which failed, if any.
+func (c *Client) execute(ctx context.Context, nation string, cmd Command, params url.Values) (string, Credentials, error) {
+	token, creds, err := c.command(ctx, nation, cmd, params, "prepare", "")
+	if err != nil {
+		return "", creds, err
+	}
+	return c.command(ctx, nation, cmd, params, "execute", token)
+}
+
+// command runs a single step of a private command without retrying. The
+// token returned when preparing a command is tied to the login session, so
+// both steps must use the same PIN, which the client keeps between requests.
+func (c *Client) command(ctx context.Context, nation string, cmd Command, cmdParams url.Values, mode, token string) (string, Credentials, error) {
+	params := make(url.Values, len(cmdParams)+4)
+	for k, v := range cmdParams {
+		params[k] = v
+	}
+	params.Set("nation", nation)
+	params.Set("c", cmd.name())
+	params.Set("mode", mode)
+	if token != "" {
+		params.Set("token", token)
+	}
+	var res commandResult
+	creds, err := c.doAuthenticated(ctx, http.MethodPost, params, &res)
+	if err != nil {
+		return "", creds, err
+	}
+	if res.Error != "" || res.Success == "" {
+		msg := res.Error
+		if msg == "" {
+			msg = "no response"
+		}
+		return "", creds, &CommandError{Command: cmd.name(), Mode: mode, Message: msg}
+	}
+	return res.Success, creds, nil
+}
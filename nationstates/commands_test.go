@@ -0,0 +1,144 @@
+package nationstates
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestExecute(t *testing.T) {
+	var modes []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("got method %s, wanted POST", r.Method)
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Error(err)
+			return
+		}
+		if got, want := r.PostForm.Get("c"), "rmbpost"; got != want {
+			t.Errorf("got command %q, wanted %q", got, want)
+		}
+		if got, want := r.PostForm.Get("text"), "hello"; got != want {
+			t.Errorf("got text %q, wanted %q", got, want)
+		}
+		mode := r.PostForm.Get("mode")
+		modes = append(modes, mode)
+		switch mode {
+		case "prepare":
+			w.Header().Set("X-Pin", "12345")
+			w.Write([]byte(`<NATION id="testlandia"><SUCCESS>token</SUCCESS></NATION>`))
+		case "execute":
+			if got, want := r.Header.Get("X-Pin"), "12345"; got != want {
+				t.Errorf("got pin %q, wanted %q", got, want)
+			}
+			if got, want := r.PostForm.Get("token"), "token"; got != want {
+				t.Errorf("got token %q, wanted %q", got, want)
+			}
+			w.Write([]byte(`<NATION id="testlandia"><SUCCESS>Your message has been lodged.</SUCCESS></NATION>`))
+		}
+	}))
+	defer server.Close()
+	c := NewClient(WithBaseURL(server.URL), WithPassword("password"))
+	msg, err := c.Execute("testlandia", RMBPostCommand{Region: "testregionia", Text: "hello"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Your message has been lodged."; msg != want {
+		t.Fatalf("got message %q, wanted %q", msg, want)
+	}
+	if len(modes) != 2 || modes[0] != "prepare" || modes[1] != "execute" {
+		t.Fatalf("got modes %v, wanted [prepare execute]", modes)
+	}
+}
+
+func TestExecuteError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<NATION id="testlandia"><ERROR>You do not have that card.</ERROR></NATION>`))
+	}))
+	defer server.Close()
+	c := NewClient(WithBaseURL(server.URL))
+	_, err := c.Execute("testlandia", GiftCardCommand{CardID: 1, Season: 2, To: "other"})
+	var cmdErr *CommandError
+	if !errors.As(err, &cmdErr) {
+		t.Fatalf("got error %v, wanted *CommandError", err)
+	}
+	if cmdErr.Mode != "prepare" || cmdErr.Message != "You do not have that card." {
+		t.Fatalf("got %+v", cmdErr)
+	}
+}
+
+func TestExecuteExpiredPin(t *testing.T) {
+	var steps []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Error(err)
+			return
+		}
+		mode := r.PostForm.Get("mode")
+		steps = append(steps, mode+":"+r.Header.Get("X-Pin")+":"+r.PostForm.Get("token"))
+		switch {
+		case mode == "prepare" && r.Header.Get("X-Pin") == "":
+			w.Header().Set("X-Pin", "2")
+			w.Write([]byte(`<NATION id="testlandia"><SUCCESS>token2</SUCCESS></NATION>`))
+		case mode == "prepare":
+			w.Write([]byte(`<NATION id="testlandia"><SUCCESS>token1</SUCCESS></NATION>`))
+		case r.Header.Get("X-Pin") == "1":
+			w.WriteHeader(http.StatusConflict)
+		default:
+			w.Write([]byte(`<NATION id="testlandia"><SUCCESS>Your message has been lodged.</SUCCESS></NATION>`))
+		}
+	}))
+	defer server.Close()
+	c := NewClient(WithBaseURL(server.URL), WithPassword("password"), WithCredentials(Credentials{Pin: "1"}))
+	_, err := c.Execute("testlandia", RMBPostCommand{Region: "testregionia", Text: "hello"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"prepare:1:", "execute:1:token1", "prepare::", "execute:2:token2"}
+	if !reflect.DeepEqual(steps, want) {
+		t.Fatalf("got steps %v, wanted %v", steps, want)
+	}
+}
+
+func TestExecuteInvalidDispatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("got request for invalid command")
+	}))
+	defer server.Close()
+	c := NewClient(WithBaseURL(server.URL))
+	for _, cmd := range []DispatchCommand{
+		{Action: "publish", Title: "t", Text: "x"},
+		{Action: DispatchEdit, Title: "t", Text: "x"},
+		{Action: DispatchRemove},
+	} {
+		if _, err := c.Execute("testlandia", cmd); err == nil {
+			t.Errorf("got no error for %+v", cmd)
+		}
+	}
+}
+
+func TestDispatchCommandParams(t *testing.T) {
+	params, err := DispatchCommand{Action: DispatchRemove, ID: 42}.params()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := params.Get("dispatchid"); got != "42" {
+		t.Fatalf("got dispatchid %q, wanted %q", got, "42")
+	}
+	if _, ok := params["title"]; ok {
+		t.Fatal("got title for remove, wanted none")
+	}
+	params, err = DispatchCommand{Action: DispatchAdd, Title: "t", Text: "x", Category: DispatchFactbook, Subcategory: DispatchFactbookOverview}.params()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := params["dispatchid"]; ok {
+		t.Fatal("got dispatchid for add, wanted none")
+	}
+	if got := params.Get("subcategory"); got != "100" {
+		t.Fatalf("got subcategory %q, wanted %q", got, "100")
+	}
+}
@@ -0,0 +1,106 @@
+package nationstates
+
+import (
+	"compress/gzip"
+	"encoding/xml"
+	"io"
+	"os"
+)
+
+// Daily data dumps of every nation and region, updated once a day.
+const (
+	NationsDumpURL = "https://www.nationstates.net/pages/nations.xml.gz"
+	RegionsDumpURL = "https://www.nationstates.net/pages/regions.xml.gz"
+)
+
+// DumpDecoder reads the nations or regions in a daily data dump one at a
+// time, so that only the current record is held in memory.
+type DumpDecoder struct {
+	dec    *xml.Decoder
+	gz     *gzip.Reader
+	closer io.Closer
+}
+
+// NewDumpDecoder returns a DumpDecoder which reads a gzipped dump from r.
+func NewDumpDecoder(r io.Reader) (*DumpDecoder, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	return &DumpDecoder{dec: xml.NewDecoder(gz), gz: gz}, nil
+}
+
+// OpenDump returns a DumpDecoder which reads the gzipped dump at path. The
+// decoder must be closed when it is no longer needed.
+func OpenDump(path string) (*DumpDecoder, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	d, err := NewDumpDecoder(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	d.closer = f
+	return d, nil
+}
+
+// NextNation decodes the next nation in a nations dump into n. It returns
+// io.EOF when there are no more nations.
+func (d *DumpDecoder) NextNation(n *Nation) error {
+	var v Nation
+	err := d.next("NATION", &v)
+	if err != nil {
+		return err
+	}
+	*n = v
+	return nil
+}
+
+// NextRegion decodes the next region in a regions dump into r. It returns
+// io.EOF when there are no more regions.
+func (d *DumpDecoder) NextRegion(r *Region) error {
+	var v Region
+	err := d.next("REGION", &v)
+	if err != nil {
+		return err
+	}
+	*r = v
+	return nil
+}
+
+// next decodes the next element called name into v, skipping the enclosing
+// NATIONS or REGIONS element.
+func (d *DumpDecoder) next(name string, v interface{}) error {
+	for {
+		tok, err := d.dec.Token()
+		if err != nil {
+			return err
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if start.Name.Local == name {
+			return d.dec.DecodeElement(v, &start)
+		}
+		if start.Name.Local != name+"S" {
+			err = d.dec.Skip()
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Close closes the dump.
+func (d *DumpDecoder) Close() error {
+	err := d.gz.Close()
+	if d.closer != nil {
+		if closeErr := d.closer.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
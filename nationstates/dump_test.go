@@ -0,0 +1,62 @@
+package nationstates
+
+import (
+	"io"
+	"testing"
+)
+
+func TestDumpNations(t *testing.T) {
+	d, err := OpenDump("testdata/nations.xml.gz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+	var names []string
+	var n Nation
+	for {
+		err := d.NextNation(&n)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, n.Name)
+		if n.Name == "Otherland" && n.Motto != "" {
+			t.Errorf("got motto %q for Otherland, wanted fields from the previous nation to be reset", n.Motto)
+		}
+	}
+	if len(names) != 2 || names[0] != "Testlandia" || names[1] != "Otherland" {
+		t.Fatalf("got nations %v, wanted [Testlandia Otherland]", names)
+	}
+	if n.WAStatus != WAStatusNonMember || n.Region != "The Pacific" || n.Population != 1000 {
+		t.Fatalf("got %+v", n)
+	}
+}
+
+func TestDumpRegions(t *testing.T) {
+	d, err := OpenDump("testdata/regions.xml.gz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+	var regions []Region
+	for {
+		var r Region
+		err := d.NextRegion(&r)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		regions = append(regions, r)
+	}
+	if len(regions) != 2 {
+		t.Fatalf("got %d regions, wanted 2", len(regions))
+	}
+	r := regions[0]
+	if r.Name != "Testregionia" || r.NumNations != 1 || r.Delegate != "testlandia" || len(r.Nations) != 1 || r.Nations[0] != "testlandia" {
+		t.Fatalf("got %+v", r)
+	}
+}
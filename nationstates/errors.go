@@ -0,0 +1,98 @@
+package nationstates
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrNotFound is matched by API errors for nations, regions or other resources which do not exist.
+	ErrNotFound = errors.New("nationstates: not found")
+	// ErrForbidden is matched by API errors caused by a rejected password or autologin.
+	ErrForbidden = errors.New("nationstates: forbidden")
+	// ErrPinExpired is matched by API errors caused by an expired or invalid PIN.
+	ErrPinExpired = errors.New("nationstates: pin expired")
+	// ErrRateLimited is matched by API errors caused by exceeding the rate limit.
+	ErrRateLimited = errors.New("nationstates: rate limited")
+	// ErrServer is matched by API errors caused by NationStates being unavailable.
+	ErrServer = errors.New("nationstates: server error")
+)
+
+// APIError is returned when NationStates responds with an error. Use
+// errors.Is with the Err sentinels to find out what went wrong.
+type APIError struct {
+	StatusCode int
+	// Message is the error message returned by NationStates, stripped of any markup.
+	Message string
+	// RetryAfter is how long NationStates asked us to wait before trying again, if at all.
+	RetryAfter time.Duration
+}
+
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("nationstates: %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	return fmt.Sprintf("nationstates: %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrPinExpired:
+		return e.StatusCode == http.StatusConflict
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrServer:
+		return e.StatusCode >= http.StatusInternalServerError
+	}
+	return false
+}
+
+// DecodeError is returned when NationStates responds successfully but the
+// response cannot be decoded, for example because a maintenance page was
+// served in place of XML. It wraps the error from the decoder.
+type DecodeError struct {
+	// Message is the text of the response, stripped of any markup.
+	Message string
+	Err     error
+}
+
+func (e *DecodeError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("nationstates: cannot decode response: %v", e.Err)
+	}
+	return fmt.Sprintf("nationstates: cannot decode response: %v: %s", e.Err, e.Message)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// IssueError is returned by AnswerIssue when NationStates refuses to answer an issue.
+type IssueError struct {
+	IssueID  int
+	OptionID int
+	Message  string
+}
+
+func (e *IssueError) Error() string {
+	return fmt.Sprintf("nationstates: cannot answer issue %d with option %d: %s", e.IssueID, e.OptionID, e.Message)
+}
+
+var (
+	markupPattern     = regexp.MustCompile(`(?s)<(script|style)[^>]*>.*?</(script|style)>|<[^>]*>`)
+	whitespacePattern = regexp.MustCompile(`\s+`)
+)
+
+// errorMessage extracts a human readable message from an error page.
+func errorMessage(body []byte) string {
+	s := markupPattern.ReplaceAllString(string(body), " ")
+	return strings.TrimSpace(whitespacePattern.ReplaceAllString(s, " "))
+}
@@ -0,0 +1,88 @@
+package nationstates
+
+import (
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAPIErrors(t *testing.T) {
+	tests := []struct {
+		status int
+		body   string
+		want   error
+		msg    string
+	}{
+		{http.StatusNotFound, "<h1>Not Found</h1>\n<p>Unknown nation: \"nowhere\".</p>", ErrNotFound, `Not Found Unknown nation: "nowhere".`},
+		{http.StatusForbidden, "<h1>Forbidden</h1>", ErrForbidden, "Forbidden"},
+		{http.StatusConflict, "<h1>Conflict</h1>", ErrPinExpired, "Conflict"},
+		{http.StatusTooManyRequests, "Too Many Requests", ErrRateLimited, "Too Many Requests"},
+		{http.StatusBadGateway, "<html><head><style>h1 { color: red; }</style></head><body>Bad Gateway</body></html>", ErrServer, "Bad Gateway"},
+	}
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+			c := NewClient(WithBaseURL(server.URL))
+			_, err := c.GetNotices("nowhere")
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("got error %v, wanted %v", err, tt.want)
+			}
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("got error %v, wanted *APIError", err)
+			}
+			if apiErr.StatusCode != tt.status {
+				t.Errorf("got status code %d, wanted %d", apiErr.StatusCode, tt.status)
+			}
+			if apiErr.Message != tt.msg {
+				t.Errorf("got message %q, wanted %q", apiErr.Message, tt.msg)
+			}
+		})
+	}
+}
+
+func TestDecodeError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html><body><p>NationStates is down for maintenance.</p></body></html>"))
+	}))
+	defer server.Close()
+	c := NewClient(WithBaseURL(server.URL))
+	_, err := c.GetNotices("testlandia")
+	var decodeErr *DecodeError
+	if !errors.As(err, &decodeErr) {
+		t.Fatalf("got error %v, wanted *DecodeError", err)
+	}
+	var xmlErr xml.UnmarshalError
+	if !errors.As(err, &xmlErr) {
+		t.Fatalf("got error %v, wanted it to wrap xml.UnmarshalError", err)
+	}
+	if got, want := decodeErr.Message, "NationStates is down for maintenance."; got != want {
+		t.Fatalf("got message %q, wanted %q", got, want)
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		t.Fatalf("got *APIError %v, wanted only a *DecodeError", apiErr)
+	}
+}
+
+func TestAnswerIssueError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<NATION id="testlandia"><ISSUE id="1" choice="9"><ERROR>Invalid choice.</ERROR></ISSUE></NATION>`))
+	}))
+	defer server.Close()
+	c := NewClient(WithBaseURL(server.URL))
+	_, err := c.AnswerIssue("testlandia", 1, 9)
+	var issueErr *IssueError
+	if !errors.As(err, &issueErr) {
+		t.Fatalf("got error %v, wanted *IssueError", err)
+	}
+	if got, want := issueErr.Message, "Invalid choice."; got != want {
+		t.Fatalf("got message %q, wanted %q", got, want)
+	}
+}
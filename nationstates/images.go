@@ -0,0 +1,15 @@
+package nationstates
+
+// ImageBaseURL is where NationStates serves issue illustrations and banners from.
+const ImageBaseURL = "https://www.nationstates.net/images/"
+
+// PictureURL returns the URL of an issue illustration, such as the PIC1 or
+// PIC2 of an Issue.
+func PictureURL(pic string) string {
+	return ImageBaseURL + "dilemmas/" + pic + ".jpg"
+}
+
+// BannerURL returns the URL of a banner, such as one unlocked by answering an issue.
+func BannerURL(id string) string {
+	return ImageBaseURL + "banners/" + id + ".jpg"
+}
@@ -0,0 +1,82 @@
+// Command gencensus regenerates the census catalogue from the NationStates
+// World API. Names, titles, units and descriptions of nations which score
+// highly come from the API, while categories, directions and descriptions of
+// nations which score poorly are kept from the existing catalogue.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/yi-jiayu/nationstates-secretary/nationstates"
+)
+
+func main() {
+	output := flag.String("o", "census.json", "catalogue to update")
+	maxID := flag.Int("max", 88, "highest census scale ID to fetch")
+	userAgent := flag.String("user-agent", "", "User-Agent identifying who is running the generator")
+	flag.Parse()
+
+	existing := make(map[int]nationstates.CensusInfo)
+	data, err := ioutil.ReadFile(*output)
+	if err != nil && !os.IsNotExist(err) {
+		log.Fatal(err)
+	}
+	if err == nil {
+		var scales []nationstates.CensusInfo
+		err = json.Unmarshal(data, &scales)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, scale := range scales {
+			existing[scale.ID] = scale
+		}
+	}
+
+	var opts []nationstates.ClientOption
+	if *userAgent != "" {
+		opts = append(opts, nationstates.WithUserAgent(*userAgent))
+	}
+	client := nationstates.NewClient(opts...)
+	var scales []nationstates.CensusInfo
+	for id := 0; id <= *maxID; id++ {
+		w, err := client.GetWorld([]nationstates.Shard{
+			nationstates.ShardCensusName,
+			nationstates.ShardCensusTitle,
+			nationstates.ShardCensusScale,
+			nationstates.ShardCensusDesc,
+		}, nationstates.CensusScales{id})
+		if err != nil {
+			log.Fatalf("scale %d: %v", id, err)
+		}
+		if w.CensusName.Text == "" {
+			log.Printf("scale %d: no such scale", id)
+			continue
+		}
+		info := existing[id]
+		info.ID = id
+		info.Name = strings.TrimSpace(w.CensusName.Text)
+		info.Title = strings.TrimSpace(w.CensusTitle.Text)
+		info.Unit = strings.TrimSpace(w.CensusUnit.Text)
+		info.High = strings.TrimSpace(w.CensusDesc.Nation)
+		scales = append(scales, info)
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "\t")
+	err = enc.Encode(scales)
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = ioutil.WriteFile(*output, buf.Bytes(), 0644)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,116 @@
+package nationstates
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// RateLimitRequests is the number of requests NationStates allows per RateLimitWindow.
+	RateLimitRequests = 50
+	// RateLimitWindow is the window over which NationStates counts requests.
+	RateLimitWindow = 30 * time.Second
+)
+
+// rateLimiter keeps track of requests made within a sliding window and
+// delays callers which would exceed the budget. It is safe for concurrent use.
+type rateLimiter struct {
+	limit  int
+	window time.Duration
+
+	mu     sync.Mutex
+	sent   []time.Time
+	resume time.Time
+}
+
+func newRateLimiter(limit int, window time.Duration) *rateLimiter {
+	return &rateLimiter{
+		limit:  limit,
+		window: window,
+	}
+}
+
+// reserve records a request at now if the budget allows it, otherwise it
+// returns how long the caller should wait before trying again.
+func (l *rateLimiter) reserve(now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.expire(now)
+	if now.Before(l.resume) {
+		return l.resume.Sub(now)
+	}
+	if len(l.sent) >= l.limit {
+		return l.sent[0].Add(l.window).Sub(now)
+	}
+	l.sent = append(l.sent, now)
+	return 0
+}
+
+// wait blocks until a request can be made without exceeding the budget or
+// ctx is done.
+func (l *rateLimiter) wait(ctx context.Context) error {
+	for {
+		d := l.reserve(time.Now())
+		if d <= 0 {
+			return nil
+		}
+		timer := time.NewTimer(d)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// expire forgets requests which have fallen out of the window.
+func (l *rateLimiter) expire(now time.Time) {
+	i := 0
+	for i < len(l.sent) && !now.Before(l.sent[i].Add(l.window)) {
+		i++
+	}
+	l.sent = l.sent[i:]
+}
+
+// update synchronises the limiter with the rate limit headers returned by
+// NationStates, which also counts requests made by other clients sharing our IP.
+func (l *rateLimiter) update(h http.Header) {
+	now := time.Now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.expire(now)
+	if remaining, err := strconv.Atoi(h.Get("X-Ratelimit-Remaining")); err == nil {
+		for l.limit-len(l.sent) > remaining {
+			l.sent = append(l.sent, now)
+		}
+	}
+	if retryAfter := parseRetryAfter(h); retryAfter > 0 {
+		l.pauseLocked(now, retryAfter)
+	}
+}
+
+// pause stops all requests from being made for d.
+func (l *rateLimiter) pause(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.pauseLocked(time.Now(), d)
+}
+
+func (l *rateLimiter) pauseLocked(now time.Time, d time.Duration) {
+	if resume := now.Add(d); resume.After(l.resume) {
+		l.resume = resume
+	}
+}
+
+// parseRetryAfter parses the Retry-After header, which NationStates sends in seconds.
+func parseRetryAfter(h http.Header) time.Duration {
+	seconds, err := strconv.Atoi(h.Get("Retry-After"))
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
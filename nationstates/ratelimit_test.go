@@ -0,0 +1,70 @@
+package nationstates
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterReserve(t *testing.T) {
+	l := newRateLimiter(2, 30*time.Second)
+	start := time.Now()
+	for i := 0; i < 2; i++ {
+		if d := l.reserve(start); d != 0 {
+			t.Fatalf("request %d: got wait %s, wanted 0", i, d)
+		}
+	}
+	if d := l.reserve(start.Add(10 * time.Second)); d != 20*time.Second {
+		t.Fatalf("got wait %s, wanted %s", d, 20*time.Second)
+	}
+	if d := l.reserve(start.Add(30 * time.Second)); d != 0 {
+		t.Fatalf("got wait %s after window elapsed, wanted 0", d)
+	}
+}
+
+func TestRateLimiterUpdate(t *testing.T) {
+	l := newRateLimiter(50, 30*time.Second)
+	h := http.Header{}
+	h.Set("X-Ratelimit-Remaining", "0")
+	l.update(h)
+	if d := l.reserve(time.Now()); d <= 0 {
+		t.Fatalf("got wait %s with no remaining requests, wanted > 0", d)
+	}
+}
+
+func TestTooManyRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "900")
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte("<html>Too Many Requests</html>"))
+	}))
+	defer server.Close()
+	c := NewClient(WithBaseURL(server.URL))
+	_, err := c.GetNation("testlandia", []Shard{ShardNotices})
+	if !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("got error %v, wanted ErrRateLimited", err)
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("got error %v, wanted *APIError", err)
+	}
+	if got, want := apiErr.RetryAfter, 900*time.Second; got != want {
+		t.Fatalf("got retry after %s, wanted %s", got, want)
+	}
+	if d := c.rateLimiter().reserve(time.Now()); d <= 0 {
+		t.Fatalf("got wait %s after 429, wanted > 0", d)
+	}
+}
+
+func TestRateLimiterWaitContext(t *testing.T) {
+	l := newRateLimiter(1, time.Hour)
+	l.reserve(time.Now())
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := l.wait(ctx); err != context.Canceled {
+		t.Fatalf("got error %v, wanted %v", err, context.Canceled)
+	}
+}
@@ -0,0 +1,134 @@
+package nationstates
+
+import (
+	"context"
+	"encoding/xml"
+	"strings"
+)
+
+// Region holds the shards returned by the Region API.
+type Region struct {
+	XMLName  xml.Name `xml:"REGION"`
+	ID       string   `xml:"id,attr"`
+	Name     string   `xml:"NAME"`
+	Delegate string   `xml:"DELEGATE"`
+	// DelegateVotes is the number of votes the delegate casts in the World
+	// Assembly, which is one more than their number of endorsements.
+	DelegateVotes int `xml:"DELEGATEVOTES"`
+	// DelegateAuth and FounderAuth are authority codes as for
+	// Officer.Authority, where "X" marks an executive delegate.
+	DelegateAuth string    `xml:"DELEGATEAUTH"`
+	Founder      string    `xml:"FOUNDER"`
+	FounderAuth  string    `xml:"FOUNDERAUTH"`
+	Officers     []Officer `xml:"OFFICERS>OFFICER"`
+	NumNations   int       `xml:"NUMNATIONS"`
+	Nations      NameList  `xml:"NATIONS"`
+	// Messages are the latest posts on the Regional Message Board, oldest
+	// first. Use Limit and Offset to page back through older posts.
+	Messages []Post `xml:"MESSAGES>POST"`
+	// Happenings are the region's recent happenings, newest first.
+	Happenings []Event   `xml:"HAPPENINGS>EVENT"`
+	Embassies  []Embassy `xml:"EMBASSIES>EMBASSY"`
+	GAVote     WAVote    `xml:"GAVOTE"`
+	SCVote     WAVote    `xml:"SCVOTE"`
+	// Census holds the region's scores, which NationStates derives from the
+	// scores of its nations.
+	Census []CensusScale `xml:"CENSUS>SCALE"`
+	Tags   []string      `xml:"TAGS>TAG"`
+}
+
+// Officer is a regional officer. Authority is a string of authority codes,
+// for example "BCE" for border control, communications and embassies.
+type Officer struct {
+	Nation    string `xml:"NATION"`
+	Office    string `xml:"OFFICE"`
+	Authority string `xml:"AUTHORITY"`
+	// Time is when the officer was appointed, as a Unix timestamp, and By is
+	// the nation which appointed them.
+	Time int    `xml:"TIME"`
+	By   string `xml:"BY"`
+	// Order is the position of the office in the region's list of officers.
+	Order int `xml:"ORDER"`
+}
+
+// Post is a message on a Regional Message Board.
+type Post struct {
+	ID int `xml:"id,attr"`
+	// Timestamp is when the post was made, as a Unix timestamp.
+	Timestamp int    `xml:"TIMESTAMP"`
+	Nation    string `xml:"NATION"`
+	// Status is one of the PostStatus constants.
+	Status  int      `xml:"STATUS"`
+	Likes   int      `xml:"LIKES"`
+	Likers  NameList `xml:"LIKERS"`
+	Message string   `xml:"MESSAGE"`
+}
+
+const (
+	PostStatusNormal     = 0
+	PostStatusSuppressed = 1
+	PostStatusDeleted    = 2
+	PostStatusModerated  = 9
+)
+
+// Event is an entry in a happenings feed.
+type Event struct {
+	ID int `xml:"id,attr"`
+	// Timestamp is when the event happened, as a Unix timestamp.
+	Timestamp int `xml:"TIMESTAMP"`
+	// Text describes the event, with nation names wrapped in @@ and region
+	// names wrapped in %%.
+	Text string `xml:"TEXT"`
+}
+
+const (
+	EmbassyEstablished = ""
+	EmbassyPending     = "pending"
+	EmbassyInvited     = "invited"
+	EmbassyRequested   = "requested"
+	EmbassyRejected    = "rejected"
+	EmbassyDenied      = "denied"
+	EmbassyClosing     = "closing"
+)
+
+// Embassy is an embassy with another region. Type is empty for established
+// embassies and one of the Embassy constants otherwise.
+type Embassy struct {
+	Type   string `xml:"type,attr"`
+	Region string `xml:",chardata"`
+}
+
+// WAVote is the tally of votes cast by a region's nations on the resolution
+// currently at vote in a World Assembly council.
+type WAVote struct {
+	For     int `xml:"FOR"`
+	Against int `xml:"AGAINST"`
+}
+
+// NameList is a list of nation or region names which NationStates separates
+// with colons.
+type NameList []string
+
+func (l *NameList) UnmarshalText(text []byte) error {
+	s := strings.TrimSpace(string(text))
+	if s == "" {
+		*l = nil
+		return nil
+	}
+	*l = strings.Split(s, ":")
+	return nil
+}
+
+// GetRegion is a generic method for querying the Region API.
+func (c *Client) GetRegion(region string, shards []Shard, params ...Param) (Region, error) {
+	return c.GetRegionContext(context.Background(), region, shards, params...)
+}
+
+// GetRegionContext is like GetRegion but with a context.
+func (c *Client) GetRegionContext(ctx context.Context, region string, shards []Shard, params ...Param) (Region, error) {
+	q := query(shards, params)
+	q.Set("region", region)
+	var r Region
+	err := c.doPublic(ctx, q, &r)
+	return r, err
+}
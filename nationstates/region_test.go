@@ -0,0 +1,104 @@
+package nationstates
+
+import (
+	"encoding/xml"
+	"reflect"
+	"testing"
+)
+
+func TestUnmarshalRegion(t *testing.T) {
+	s := `<REGION id="testregionia">
+  <NAME>Testregionia</NAME>
+  <DELEGATE>testlandia</DELEGATE>
+  <DELEGATEVOTES>13</DELEGATEVOTES>
+  <DELEGATEAUTH>XWABCEP</DELEGATEAUTH>
+  <FOUNDER>0</FOUNDER>
+  <FOUNDERAUTH>XWABCEP</FOUNDERAUTH>
+  <OFFICERS>
+    <OFFICER>
+      <NATION>wilbert</NATION>
+      <OFFICE>Minister of Foreign Affairs</OFFICE>
+      <AUTHORITY>E</AUTHORITY>
+      <TIME>1581740400</TIME>
+      <BY>testlandia</BY>
+      <ORDER>1</ORDER>
+    </OFFICER>
+  </OFFICERS>
+  <NUMNATIONS>3</NUMNATIONS>
+  <NATIONS>testlandia:wilbert:maxtopia</NATIONS>
+  <MESSAGES>
+    <POST id="123456">
+      <TIMESTAMP>1581740460</TIMESTAMP>
+      <NATION>wilbert</NATION>
+      <STATUS>0</STATUS>
+      <LIKES>2</LIKES>
+      <LIKERS>testlandia:maxtopia</LIKERS>
+      <MESSAGE>Hello, world!</MESSAGE>
+    </POST>
+  </MESSAGES>
+  <HAPPENINGS>
+    <EVENT id="987654">
+      <TIMESTAMP>1581740500</TIMESTAMP>
+      <TEXT>@@maxtopia@@ relocated from %%lazarus%% to %%testregionia%%.</TEXT>
+    </EVENT>
+  </HAPPENINGS>
+  <EMBASSIES>
+    <EMBASSY>the pacific</EMBASSY>
+    <EMBASSY type="pending">lazarus</EMBASSY>
+  </EMBASSIES>
+  <GAVOTE>
+    <FOR>2</FOR>
+    <AGAINST>1</AGAINST>
+  </GAVOTE>
+  <CENSUS>
+    <SCALE id="65">
+      <SCORE>1234.5</SCORE>
+      <RANK>512</RANK>
+      <PRANK>3</PRANK>
+    </SCALE>
+  </CENSUS>
+  <TAGS>
+    <TAG>Small</TAG>
+    <TAG>Democratic</TAG>
+  </TAGS>
+</REGION>
+`
+	var got Region
+	err := xml.Unmarshal([]byte(s), &got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Region{
+		XMLName:       xml.Name{Local: "REGION"},
+		ID:            "testregionia",
+		Name:          "Testregionia",
+		Delegate:      "testlandia",
+		DelegateVotes: 13,
+		DelegateAuth:  "XWABCEP",
+		Founder:       "0",
+		FounderAuth:   "XWABCEP",
+		Officers: []Officer{
+			{Nation: "wilbert", Office: "Minister of Foreign Affairs", Authority: "E", Time: 1581740400, By: "testlandia", Order: 1},
+		},
+		NumNations: 3,
+		Nations:    NameList{"testlandia", "wilbert", "maxtopia"},
+		Messages: []Post{
+			{ID: 123456, Timestamp: 1581740460, Nation: "wilbert", Likes: 2, Likers: NameList{"testlandia", "maxtopia"}, Message: "Hello, world!"},
+		},
+		Happenings: []Event{
+			{ID: 987654, Timestamp: 1581740500, Text: "@@maxtopia@@ relocated from %%lazarus%% to %%testregionia%%."},
+		},
+		Embassies: []Embassy{
+			{Type: EmbassyEstablished, Region: "the pacific"},
+			{Type: EmbassyPending, Region: "lazarus"},
+		},
+		GAVote: WAVote{For: 2, Against: 1},
+		Census: []CensusScale{
+			{ID: CensusInfluence, Score: 1234.5, Rank: 512, PRank: 3},
+		},
+		Tags: []string{"Small", "Democratic"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %+v, wanted %+v", got, want)
+	}
+}
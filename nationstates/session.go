@@ -0,0 +1,158 @@
+package nationstates
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// Credentials are the login credentials NationStates issues for a nation. The
+// autologin token is an encrypted form of the password which remains valid
+// until the password is changed, while the PIN identifies a login session and
+// expires after a period of inactivity.
+type Credentials struct {
+	Autologin string `json:"autologin"`
+	Pin       string `json:"pin"`
+}
+
+// WithCredentialsHook registers fn to be called whenever NationStates issues
+// a new autologin token or PIN, so that they can be persisted and reused
+// instead of logging in with the password again. fn may be called from
+// multiple goroutines.
+func WithCredentialsHook(fn func(Credentials)) ClientOption {
+	return func(c *Client) {
+		c.onCredentials = fn
+	}
+}
+
+// WithPassword sets the password used to log in when there is no autologin
+// token, or when NationStates rejects it.
+func WithPassword(password string) ClientOption {
+	return func(c *Client) {
+		c.password = password
+	}
+}
+
+// WithCredentials sets the autologin token and PIN to start with, for example
+// ones saved by the credentials hook in a previous run.
+func WithCredentials(creds Credentials) ClientOption {
+	return func(c *Client) {
+		c.autologin = creds.Autologin
+		c.pin = creds.Pin
+	}
+}
+
+// Credentials returns the credentials for the current login session.
+func (c *Client) Credentials() Credentials {
+	c.fieldsOnce.Do(c.adoptFields)
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return Credentials{
+		Autologin: c.autologin,
+		Pin:       c.pin,
+	}
+}
+
+// adoptFields copies credentials set with the deprecated exported fields in
+// place of those set with options.
+func (c *Client) adoptFields() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.Password != "" {
+		c.password = c.Password
+	}
+	if c.Autologin != "" || c.Pin != "" {
+		c.autologin = c.Autologin
+		c.pin = c.Pin
+	}
+	c.mirrorLocked()
+}
+
+// mirrorLocked copies the current credentials to the deprecated exported
+// fields. c.mu must be held.
+func (c *Client) mirrorLocked() {
+	c.Password = c.password
+	c.Autologin = c.autologin
+	c.Pin = c.pin
+}
+
+// Login logs in to nation with the configured password or autologin token
+// and records the credentials returned by NationStates.
+func (c *Client) Login(ctx context.Context, nation string) error {
+	_, err := c.GetNationContext(ctx, nation, []Shard{ShardPing})
+	return err
+}
+
+// canLogin reports whether c has credentials it can use to start a new login
+// session without a PIN.
+func (c *Client) canLogin() bool {
+	creds := c.Credentials()
+	return c.password != "" || creds.Autologin != ""
+}
+
+// setHeaders adds authentication headers for creds to req. The autologin
+// token is preferred over the password, and NationStates falls back to
+// either if the PIN has expired.
+func (c *Client) setHeaders(req *http.Request, creds Credentials) {
+	if creds.Autologin != "" {
+		req.Header.Set("X-Autologin", creds.Autologin)
+	} else if c.password != "" {
+		req.Header.Set("X-Password", c.password)
+	}
+	if creds.Pin != "" {
+		req.Header.Set("X-Pin", creds.Pin)
+	}
+}
+
+// updateCredentials records the autologin token and PIN returned in h, and
+// notifies the credentials hook if either of them changed.
+func (c *Client) updateCredentials(h http.Header) {
+	c.mu.Lock()
+	changed := false
+	if pin := h.Get("X-Pin"); pin != "" && pin != c.pin {
+		c.pin = pin
+		changed = true
+	}
+	if autologin := h.Get("X-Autologin"); autologin != "" && autologin != c.autologin {
+		c.autologin = autologin
+		changed = true
+	}
+	c.mirrorLocked()
+	creds := Credentials{Autologin: c.autologin, Pin: c.pin}
+	c.mu.Unlock()
+	if changed && c.onCredentials != nil {
+		c.onCredentials(creds)
+	}
+}
+
+// expire discards credentials which NationStates rejected, and reports
+// whether the request should be retried with the credentials that remain.
+// Credentials are only discarded if they have not been replaced by a
+// concurrent request in the meantime.
+func (c *Client) expire(used Credentials, err error) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	defer c.mirrorLocked()
+	switch {
+	case errors.Is(err, ErrPinExpired):
+		if used.Pin == "" {
+			return false
+		}
+		if c.pin == used.Pin {
+			c.pin = ""
+		}
+		return c.password != "" || c.autologin != ""
+	case errors.Is(err, ErrForbidden):
+		if used.Autologin == "" || c.password == "" {
+			return false
+		}
+		// the autologin token is invalidated when the password changes, so
+		// log in with the password to obtain a new one
+		if c.autologin == used.Autologin {
+			c.autologin = ""
+			c.pin = ""
+		}
+		return true
+	}
+	return false
+}
@@ -0,0 +1,114 @@
+package nationstates
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// fakeLogin is a fake NationStates server which accepts a single password,
+// issuing an autologin token and a PIN in exchange for it.
+type fakeLogin struct {
+	password  string
+	autologin string
+	pin       string
+
+	mu     sync.Mutex
+	logins int
+}
+
+func (f *fakeLogin) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch {
+	case r.Header.Get("X-Pin") != "" && r.Header.Get("X-Pin") == f.pin:
+	case r.Header.Get("X-Pin") != "":
+		w.WriteHeader(http.StatusConflict)
+		return
+	case r.Header.Get("X-Autologin") != "" && r.Header.Get("X-Autologin") == f.autologin,
+		r.Header.Get("X-Password") != "" && r.Header.Get("X-Password") == f.password:
+		f.logins++
+		w.Header().Set("X-Autologin", f.autologin)
+		w.Header().Set("X-Pin", f.pin)
+	default:
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	w.Write([]byte(`<NATION id="testlandia"><PING>1</PING></NATION>`))
+}
+
+func TestLogin(t *testing.T) {
+	f := &fakeLogin{password: "hunter2", autologin: "encrypted", pin: "12345"}
+	server := httptest.NewServer(f)
+	defer server.Close()
+	var saved []Credentials
+	c := NewClient(WithBaseURL(server.URL), WithCredentialsHook(func(creds Credentials) {
+		saved = append(saved, creds)
+	}), WithPassword("hunter2"))
+	err := c.Login(context.Background(), "testlandia")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Credentials{Autologin: "encrypted", Pin: "12345"}
+	if got := c.Credentials(); got != want {
+		t.Fatalf("got credentials %+v, wanted %+v", got, want)
+	}
+	if len(saved) != 1 || saved[0] != want {
+		t.Fatalf("got saved credentials %+v, wanted [%+v]", saved, want)
+	}
+}
+
+func TestLoginDeprecatedFields(t *testing.T) {
+	f := &fakeLogin{password: "hunter2", autologin: "encrypted", pin: "12345"}
+	server := httptest.NewServer(f)
+	defer server.Close()
+	c := NewClient(WithBaseURL(server.URL))
+	c.Password = "hunter2"
+	c.Autologin = "stale"
+	err := c.Login(context.Background(), "testlandia")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Credentials{Autologin: "encrypted", Pin: "12345"}
+	if got := c.Credentials(); got != want {
+		t.Fatalf("got credentials %+v, wanted %+v", got, want)
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if got := (Credentials{Autologin: c.Autologin, Pin: c.Pin}); got != want {
+		t.Fatalf("got deprecated fields %+v, wanted them to mirror %+v", got, want)
+	}
+}
+
+func TestLoginStaleAutologin(t *testing.T) {
+	f := &fakeLogin{password: "hunter2", autologin: "encrypted", pin: "12345"}
+	server := httptest.NewServer(f)
+	defer server.Close()
+	c := NewClient(WithBaseURL(server.URL), WithPassword("hunter2"), WithCredentials(Credentials{Autologin: "stale"}))
+	_, err := c.GetNotices("testlandia")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := c.Credentials().Autologin, "encrypted"; got != want {
+		t.Fatalf("got autologin %q, wanted %q", got, want)
+	}
+}
+
+func TestLoginExpiredPin(t *testing.T) {
+	f := &fakeLogin{password: "hunter2", autologin: "encrypted", pin: "12345"}
+	server := httptest.NewServer(f)
+	defer server.Close()
+	c := NewClient(WithBaseURL(server.URL), WithCredentials(Credentials{Autologin: "encrypted", Pin: "expired"}))
+	_, err := c.GetNotices("testlandia")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := c.Credentials().Pin, "12345"; got != want {
+		t.Fatalf("got pin %q, wanted %q", got, want)
+	}
+	if f.logins != 1 {
+		t.Fatalf("got %d logins, wanted 1", f.logins)
+	}
+}
@@ -0,0 +1,307 @@
+package nationstates
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Shard is the name of a piece of data which can be requested from the API.
+// Shards are shared by the Nation, Region, World and World Assembly APIs
+// where their names coincide.
+type Shard string
+
+// Nation API shards.
+const (
+	ShardAdmirables     Shard = "admirables"
+	ShardAnimal         Shard = "animal"
+	ShardAnimalTrait    Shard = "animaltrait"
+	ShardAnswered       Shard = "answered"
+	ShardBanner         Shard = "banner"
+	ShardBanners        Shard = "banners"
+	ShardCapital        Shard = "capital"
+	ShardCategory       Shard = "category"
+	ShardCensus         Shard = "census"
+	ShardCrime          Shard = "crime"
+	ShardCurrency       Shard = "currency"
+	ShardDBID           Shard = "dbid"
+	ShardDeaths         Shard = "deaths"
+	ShardDemonym        Shard = "demonym"
+	ShardDemonym2       Shard = "demonym2"
+	ShardDemonym2Plural Shard = "demonym2plural"
+	ShardDispatches     Shard = "dispatches"
+	ShardEndorsements   Shard = "endorsements"
+	ShardFactbooks      Shard = "factbooks"
+	ShardFirstLogin     Shard = "firstlogin"
+	ShardFlag           Shard = "flag"
+	ShardFounded        Shard = "founded"
+	ShardFoundedTime    Shard = "foundedtime"
+	ShardFreedom        Shard = "freedom"
+	ShardFreedomScores  Shard = "freedomscores"
+	ShardFullName       Shard = "fullname"
+	ShardGAVote         Shard = "gavote"
+	ShardGDP            Shard = "gdp"
+	ShardGovt           Shard = "govt"
+	ShardGovtDesc       Shard = "govtdesc"
+	ShardGovtPriority   Shard = "govtpriority"
+	ShardHappenings     Shard = "happenings"
+	ShardIncome         Shard = "income"
+	ShardIndustryDesc   Shard = "industrydesc"
+	ShardInfluence      Shard = "influence"
+	ShardIssues         Shard = "issues"
+	ShardIssuesAnswered Shard = "issuesanswered"
+	ShardIssueSummary   Shard = "issuesummary"
+	ShardLastActivity   Shard = "lastactivity"
+	ShardLastLogin      Shard = "lastlogin"
+	ShardLeader         Shard = "leader"
+	ShardLegislation    Shard = "legislation"
+	ShardMajorIndustry  Shard = "majorindustry"
+	ShardMotto          Shard = "motto"
+	ShardName           Shard = "name"
+	ShardNextIssue      Shard = "nextissue"
+	ShardNextIssueTime  Shard = "nextissuetime"
+	ShardNotables       Shard = "notables"
+	ShardNotices        Shard = "notices"
+	ShardPing           Shard = "ping"
+	ShardPolicies       Shard = "policies"
+	ShardPoorest        Shard = "poorest"
+	ShardPopulation     Shard = "population"
+	ShardPublicSector   Shard = "publicsector"
+	ShardRCensus        Shard = "rcensus"
+	ShardRegion         Shard = "region"
+	ShardReligion       Shard = "religion"
+	ShardRichest        Shard = "richest"
+	ShardSCVote         Shard = "scvote"
+	ShardSectors        Shard = "sectors"
+	ShardSensibilities  Shard = "sensibilities"
+	ShardTax            Shard = "tax"
+	ShardTGCanRecruit   Shard = "tgcanrecruit"
+	ShardTGCanCampaign  Shard = "tgcancampaign"
+	ShardType           Shard = "type"
+	ShardWA             Shard = "wa"
+	ShardWABadges       Shard = "wabadges"
+	ShardWCensus        Shard = "wcensus"
+)
+
+// Region API shards.
+const (
+	ShardDelegate      Shard = "delegate"
+	ShardDelegateAuth  Shard = "delegateauth"
+	ShardDelegateVotes Shard = "delegatevotes"
+	ShardEmbassies     Shard = "embassies"
+	ShardFounder       Shard = "founder"
+	ShardFounderAuth   Shard = "founderauth"
+	ShardMessages      Shard = "messages"
+	ShardNations       Shard = "nations"
+	ShardNumNations    Shard = "numnations"
+	ShardOfficers      Shard = "officers"
+	ShardTags          Shard = "tags"
+)
+
+// World API shards.
+const (
+	ShardCensusDesc     Shard = "censusdesc"
+	ShardCensusName     Shard = "censusname"
+	ShardCensusRanks    Shard = "censusranks"
+	ShardCensusScale    Shard = "censusscale"
+	ShardCensusTitle    Shard = "censustitle"
+	ShardDispatchList   Shard = "dispatchlist"
+	ShardFeaturedRegion Shard = "featuredregion"
+	ShardNewNations     Shard = "newnations"
+	ShardRegionsByTag   Shard = "regionsbytag"
+)
+
+// World Assembly API shards.
+const (
+	ShardDelegates      Shard = "delegates"
+	ShardDelLog         Shard = "dellog"
+	ShardDelVotes       Shard = "delvotes"
+	ShardLastResolution Shard = "lastresolution"
+	ShardMembers        Shard = "members"
+	ShardNumDelegates   Shard = "numdelegates"
+	ShardProposals      Shard = "proposals"
+	ShardResolution     Shard = "resolution"
+	ShardVoters         Shard = "voters"
+	ShardVoteTrack      Shard = "votetrack"
+)
+
+// Cards API shards. They are requested with GetCards, or with GetCard for a
+// single card.
+const (
+	ShardAuctions    Shard = "auctions"
+	ShardCollection  Shard = "collection"
+	ShardCollections Shard = "collections"
+	ShardDeck        Shard = "deck"
+	ShardInfo        Shard = "info"
+	ShardMarkets     Shard = "markets"
+	ShardOwners      Shard = "owners"
+	ShardTrades      Shard = "trades"
+)
+
+// Param is a query parameter which modifies the shards returned by a
+// request. It can only be implemented by the types in this package, so
+// that an invalid parameter is a compile time error.
+type Param interface {
+	setParam(params url.Values)
+}
+
+// From restricts notices or census history to those after a timestamp.
+type From int
+
+func (f From) setParam(params url.Values) {
+	params.Set("from", strconv.Itoa(int(f)))
+}
+
+// To restricts census history to points before a timestamp.
+type To int
+
+func (t To) setParam(params url.Values) {
+	params.Set("to", strconv.Itoa(int(t)))
+}
+
+// Limit limits the number of happenings, messages or other entries returned.
+type Limit int
+
+func (l Limit) setParam(params url.Values) {
+	params.Set("limit", strconv.Itoa(int(l)))
+}
+
+// Offset skips the given number of entries, for example messages.
+type Offset int
+
+func (o Offset) setParam(params url.Values) {
+	params.Set("offset", strconv.Itoa(int(o)))
+}
+
+// Tags selects the regions returned by the regionsbytag shard. A tag prefixed
+// with "-" excludes regions with that tag.
+type Tags []string
+
+func (t Tags) setParam(params url.Values) {
+	params.Set("tags", strings.Join(t, ","))
+}
+
+// Start selects the rank the censusranks shard starts from, for paging
+// through the nations in a census.
+type Start int
+
+func (s Start) setParam(params url.Values) {
+	params.Set("start", strconv.Itoa(int(s)))
+}
+
+// DispatchAuthor restricts the dispatchlist shard to dispatches by a nation.
+type DispatchAuthor string
+
+func (a DispatchAuthor) setParam(params url.Values) {
+	params.Set("dispatchauthor", string(a))
+}
+
+// DispatchCategory restricts the dispatchlist shard to a category such as
+// "Factbook", or a subcategory such as "Factbook:Overview".
+type DispatchCategory string
+
+func (c DispatchCategory) setParam(params url.Values) {
+	params.Set("dispatchcategory", string(c))
+}
+
+// DispatchSort orders the dispatchlist shard.
+type DispatchSort string
+
+const (
+	DispatchSortNew  DispatchSort = "new"
+	DispatchSortBest DispatchSort = "best"
+)
+
+func (s DispatchSort) setParam(params url.Values) {
+	params.Set("dispatchsort", string(s))
+}
+
+// NationName selects the nation whose deck, deck info or collections are
+// returned by the Cards API.
+type NationName string
+
+func (n NationName) setParam(params url.Values) {
+	params.Set("nationname", string(n))
+}
+
+// CollectionID selects the collection returned by the Cards API.
+type CollectionID int
+
+func (id CollectionID) setParam(params url.Values) {
+	params.Set("collectionid", strconv.Itoa(int(id)))
+}
+
+// SinceTime restricts card trades to those after a timestamp.
+type SinceTime int
+
+func (t SinceTime) setParam(params url.Values) {
+	params.Set("sincetime", strconv.Itoa(int(t)))
+}
+
+// BeforeTime restricts card trades to those before a timestamp.
+type BeforeTime int
+
+func (t BeforeTime) setParam(params url.Values) {
+	params.Set("beforetime", strconv.Itoa(int(t)))
+}
+
+// CensusScales selects which census scales are returned by the census shards.
+type CensusScales []int
+
+// AllCensusScales selects every census scale.
+var AllCensusScales = CensusScales{-1}
+
+func (s CensusScales) setParam(params url.Values) {
+	if len(s) == 1 && s[0] < 0 {
+		params.Set("scale", "all")
+		return
+	}
+	ids := make([]string, len(s))
+	for i, id := range s {
+		ids[i] = strconv.Itoa(id)
+	}
+	params.Set("scale", strings.Join(ids, "+"))
+}
+
+// CensusMode selects which census values are returned by the census shard.
+type CensusMode string
+
+const (
+	CensusModeScore  CensusMode = "score"
+	CensusModeRank   CensusMode = "rank"
+	CensusModeRRank  CensusMode = "rrank"
+	CensusModePRank  CensusMode = "prank"
+	CensusModePRRank CensusMode = "prrank"
+	// CensusModeHistory returns past scores instead of the current ones, and
+	// cannot be combined with the other modes.
+	CensusModeHistory CensusMode = "history"
+)
+
+// CensusModes selects multiple census modes at once.
+type CensusModes []CensusMode
+
+func (m CensusMode) setParam(params url.Values) {
+	params.Set("mode", string(m))
+}
+
+func (m CensusModes) setParam(params url.Values) {
+	modes := make([]string, len(m))
+	for i, mode := range m {
+		modes[i] = string(mode)
+	}
+	params.Set("mode", strings.Join(modes, "+"))
+}
+
+// query builds the query parameters for a request for shards.
+func query(shards []Shard, params []Param) url.Values {
+	q := make([]string, len(shards))
+	for i, shard := range shards {
+		q[i] = string(shard)
+	}
+	values := url.Values{}
+	for _, param := range params {
+		param.setParam(values)
+	}
+	values.Set("q", strings.Join(q, "+"))
+	return values
+}
@@ -0,0 +1,29 @@
+package nationstates
+
+import (
+	"testing"
+)
+
+func TestQuery(t *testing.T) {
+	tests := []struct {
+		name   string
+		shards []Shard
+		params []Param
+		want   string
+	}{
+		{"notices", []Shard{ShardNotices}, []Param{From(1581740400)}, "from=1581740400&q=notices"},
+		{"census", []Shard{ShardCensus}, []Param{CensusScales{CensusCivilRights, CensusEconomy}, CensusModes{CensusModeScore, CensusModeRank}}, "mode=score%2Brank&q=census&scale=0%2B1"},
+		{"all census scales", []Shard{ShardCensus}, []Param{AllCensusScales}, "q=census&scale=all"},
+		{"regions by tag", []Shard{ShardRegionsByTag}, []Param{Tags{"fascist", "-invader"}}, "q=regionsbytag&tags=fascist%2C-invader"},
+		{"census ranks", []Shard{ShardCensusRanks}, []Param{CensusScales{CensusCivilRights}, Start(21)}, "q=censusranks&scale=0&start=21"},
+		{"dispatch list", []Shard{ShardDispatchList}, []Param{DispatchAuthor("testlandia"), DispatchCategory("Factbook:Overview"), DispatchSortBest}, "dispatchauthor=testlandia&dispatchcategory=Factbook%3AOverview&dispatchsort=best&q=dispatchlist"},
+		{"multiple shards", []Shard{ShardName, ShardMessages}, []Param{Limit(10), Offset(20)}, "limit=10&offset=20&q=name%2Bmessages"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := query(tt.shards, tt.params).Encode(); got != tt.want {
+				t.Fatalf("got %q, wanted %q", got, tt.want)
+			}
+		})
+	}
+}
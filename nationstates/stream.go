@@ -0,0 +1,165 @@
+package nationstates
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Stream reconnection delays. NationStates may ask for a different initial
+// delay with the retry field, and the delay doubles after each failed
+// connection up to the maximum.
+const (
+	StreamRetryDelay    = 3 * time.Second
+	MaxStreamRetryDelay = time.Minute
+)
+
+// NationBucket returns the stream bucket for events involving nation, which
+// may be given by its name or ID.
+func NationBucket(nation string) string {
+	return "nation:" + bucketID(nation)
+}
+
+// RegionBucket returns the stream bucket for events in region, which may be
+// given by its name or ID.
+func RegionBucket(region string) string {
+	return "region:" + bucketID(region)
+}
+
+// bucketID converts a name such as "New Testlandia" to the ID used in
+// bucket names, such as "new_testlandia".
+func bucketID(name string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(name), " ", "_"))
+}
+
+// streamEvent is the data of a server-sent event.
+type streamEvent struct {
+	Str  string `json:"str"`
+	Time int    `json:"time"`
+}
+
+// Stream calls fn with the happenings in buckets as they occur, until ctx is
+// done. It reconnects when the connection drops, resuming after the last
+// event it received, and only returns the error from ctx.
+func (c *Client) Stream(ctx context.Context, buckets []string, fn func(Event)) error {
+	var lastID string
+	delay := StreamRetryDelay
+	for {
+		received, retry, err := c.stream(ctx, buckets, &lastID, fn)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if retry > 0 {
+			delay = retry
+		} else if received {
+			delay = StreamRetryDelay
+		} else if err != nil {
+			delay *= 2
+			if delay > MaxStreamRetryDelay {
+				delay = MaxStreamRetryDelay
+			}
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// stream reads events from a single connection, updating lastID as they
+// arrive. It reports whether any events were received and the retry delay
+// requested by NationStates, if any.
+func (c *Client) stream(ctx context.Context, buckets []string, lastID *string, fn func(Event)) (bool, time.Duration, error) {
+	streamURL := DefaultStreamURL
+	if c.streamURL != "" {
+		streamURL = c.streamURL
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(streamURL, "/")+"/"+strings.Join(buckets, "+"), nil)
+	if err != nil {
+		return false, 0, err
+	}
+	userAgent := DefaultUserAgent
+	if c.userAgent != "" {
+		userAgent = c.userAgent
+	}
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Accept", "text/event-stream")
+	if *lastID != "" {
+		req.Header.Set("Last-Event-ID", *lastID)
+	}
+	client := http.DefaultClient
+	if c.client != nil {
+		client = c.client
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return false, 0, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return false, parseRetryAfter(res.Header), &APIError{StatusCode: res.StatusCode, RetryAfter: parseRetryAfter(res.Header)}
+	}
+
+	var received bool
+	var retry time.Duration
+	var id string
+	var data []string
+	scanner := bufio.NewScanner(res.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			// a blank line dispatches the event
+			if len(data) > 0 {
+				if id != "" {
+					*lastID = id
+				}
+				e, err := parseStreamEvent(id, strings.Join(data, "\n"))
+				if err == nil {
+					received = true
+					fn(e)
+				}
+			}
+			id, data = "", nil
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			// comments keep the connection alive
+			continue
+		}
+		field, value := line, ""
+		if i := strings.IndexByte(line, ':'); i >= 0 {
+			field, value = line[:i], strings.TrimPrefix(line[i+1:], " ")
+		}
+		switch field {
+		case "id":
+			id = value
+		case "data":
+			data = append(data, value)
+		case "retry":
+			if ms, err := strconv.Atoi(value); err == nil {
+				retry = time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
+	return received, retry, scanner.Err()
+}
+
+func parseStreamEvent(id, data string) (Event, error) {
+	var se streamEvent
+	err := json.Unmarshal([]byte(data), &se)
+	if err != nil {
+		return Event{}, fmt.Errorf("nationstates: invalid stream event: %w", err)
+	}
+	e := Event{Timestamp: se.Time, Text: se.Str}
+	e.ID, _ = strconv.Atoi(id)
+	return e, nil
+}
@@ -0,0 +1,65 @@
+package nationstates
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestStreamResume(t *testing.T) {
+	var connections int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got, want := r.URL.Path, "/api/nation:testlandia+region:testregionia"; got != want {
+			t.Errorf("got path %q, wanted %q", got, want)
+		}
+		connections++
+		w.Header().Set("Content-Type", "text/event-stream")
+		switch connections {
+		case 1:
+			if id := r.Header.Get("Last-Event-ID"); id != "" {
+				t.Errorf("got Last-Event-ID %q on first connection, wanted none", id)
+			}
+			// the connection drops after the first event
+			fmt.Fprint(w, "retry: 1\n: keepalive\n\nid: 100\ndata: {\"str\":\"@@testlandia@@ was endorsed by @@other@@.\",\"time\":1600000000}\n\n")
+		default:
+			if got, want := r.Header.Get("Last-Event-ID"), "100"; got != want {
+				t.Errorf("got Last-Event-ID %q, wanted %q", got, want)
+			}
+			fmt.Fprint(w, "id: 101\ndata: {\"str\":\"@@testlandia@@ changed its national motto.\",\"time\":1600000001}\n\n")
+		}
+	}))
+	defer server.Close()
+	c := NewClient(WithStreamURL(server.URL + "/api/"))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var events []Event
+	err := c.Stream(ctx, []string{NationBucket("Testlandia"), RegionBucket("testregionia")}, func(e Event) {
+		events = append(events, e)
+		if len(events) == 2 {
+			cancel()
+		}
+	})
+	if err != context.Canceled {
+		t.Fatalf("got error %v, wanted %v", err, context.Canceled)
+	}
+	if events[0].ID != 100 || events[0].Timestamp != 1600000000 || events[0].Text != "@@testlandia@@ was endorsed by @@other@@." {
+		t.Errorf("got first event %+v", events[0])
+	}
+	if events[1].ID != 101 {
+		t.Errorf("got second event %+v", events[1])
+	}
+}
+
+func TestBuckets(t *testing.T) {
+	if got, want := NationBucket("New Testlandia"), "nation:new_testlandia"; got != want {
+		t.Errorf("got %q, wanted %q", got, want)
+	}
+	if got, want := RegionBucket("The North Pacific"), "region:the_north_pacific"; got != want {
+		t.Errorf("got %q, wanted %q", got, want)
+	}
+	if got, want := NationBucket("new_testlandia"), "nation:new_testlandia"; got != want {
+		t.Errorf("got %q, wanted %q", got, want)
+	}
+}
@@ -0,0 +1,356 @@
+package nationstates
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/yi-jiayu/nationstates-secretary/internal/atomicfile"
+)
+
+// Telegram rate limits. They apply per client key in addition to the API
+// rate limit, and a recruitment telegram also counts towards the
+// non-recruitment limit.
+const (
+	TelegramInterval            = 30 * time.Second
+	RecruitmentTelegramInterval = 180 * time.Second
+)
+
+// Telegram retry delays. When a telegram cannot be sent because of a
+// transient error, the queue waits before trying again, doubling the delay
+// after each consecutive failure up to the maximum.
+const (
+	TelegramRetryDelay    = time.Minute
+	MaxTelegramRetryDelay = time.Hour
+)
+
+// Telegram is a telegram template which has been sent to tag:api, identified
+// by its telegram ID and secret key.
+type Telegram struct {
+	ID        string `json:"id"`
+	SecretKey string `json:"secret_key"`
+	// Recruitment must be set for recruitment telegrams, which are subject to
+	// a stricter rate limit.
+	Recruitment bool `json:"recruitment"`
+}
+
+// TelegramError is returned when NationStates refuses to queue a telegram.
+type TelegramError struct {
+	To      string
+	Message string
+}
+
+func (e *TelegramError) Error() string {
+	return fmt.Sprintf("nationstates: cannot send telegram to %s: %s", e.To, e.Message)
+}
+
+// SendTelegram sends tg to the nation to using clientKey. It does not enforce
+// the telegram rate limits, which is left to TelegramQueue. Telegrams are sent
+// without the nation's credentials, since the secret key authorises them.
+func (c *Client) SendTelegram(clientKey string, tg Telegram, to string) error {
+	return c.SendTelegramContext(context.Background(), clientKey, tg, to)
+}
+
+// SendTelegramContext is like SendTelegram but with a context.
+func (c *Client) SendTelegramContext(ctx context.Context, clientKey string, tg Telegram, to string) error {
+	params := url.Values{
+		"a":      {"sendTG"},
+		"client": {clientKey},
+		"tgid":   {tg.ID},
+		"key":    {tg.SecretKey},
+		"to":     {to},
+	}
+	var body []byte
+	err := c.doPublic(ctx, params, &body)
+	if err != nil {
+		return err
+	}
+	if msg := errorMessage(body); msg != "queued" {
+		return &TelegramError{To: to, Message: msg}
+	}
+	return nil
+}
+
+// TelegramRejected reports whether err means that NationStates refused the
+// telegram itself, so that sending it again would fail in the same way.
+// Other errors, such as network errors, server errors and rate limiting, are
+// transient.
+func TelegramRejected(err error) bool {
+	var tgErr *TelegramError
+	if errors.As(err, &tgErr) {
+		return true
+	}
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode >= 400 && apiErr.StatusCode < 500 && apiErr.StatusCode != http.StatusTooManyRequests
+}
+
+// QueuedTelegram is a telegram waiting to be sent.
+type QueuedTelegram struct {
+	Telegram Telegram `json:"telegram"`
+	To       string   `json:"to"`
+	// Campaign is the name of the recruitment campaign the telegram was
+	// queued for, if any.
+	Campaign string `json:"campaign,omitempty"`
+}
+
+// TelegramQueue sends telegrams in the order they were queued while
+// respecting the telegram rate limits. Non-recruitment telegrams may overtake
+// recruitment telegrams which are waiting for the stricter limit. If Path is
+// set, the queue and the time of the last telegrams are saved there so that
+// they survive restarts. A TelegramQueue must be created with
+// NewTelegramQueue.
+type TelegramQueue struct {
+	Client    *Client
+	ClientKey string
+	Path      string
+	// OnError is called when a telegram cannot be sent. The telegram is
+	// dropped if TelegramRejected reports true for the error, and otherwise
+	// stays in the queue and is retried after a delay.
+	OnError func(QueuedTelegram, error)
+	// OnSent is called after a telegram has been sent.
+	OnSent func(QueuedTelegram)
+	// Skip is called before a telegram is sent. If it reports true, the
+	// telegram is dropped without being sent, and if it returns an error,
+	// the telegram is retried after a delay as for a transient error.
+	// OnError is not called for errors from Skip.
+	Skip func(context.Context, QueuedTelegram) (bool, error)
+	// OnSaveError is called when the queue cannot be saved to Path. The queue
+	// keeps running, but changes since the last successful save would be
+	// lost on restart.
+	OnSaveError func(error)
+
+	mu    sync.Mutex
+	state telegramQueueState
+	// retryAt is when sending may resume after a transient error, and
+	// failures counts the consecutive transient errors.
+	retryAt  time.Time
+	failures int
+	// wake is signalled when a telegram is queued.
+	wake chan struct{}
+}
+
+type telegramQueueState struct {
+	Pending         []QueuedTelegram `json:"pending"`
+	LastSent        time.Time        `json:"last_sent"`
+	LastRecruitment time.Time        `json:"last_recruitment"`
+}
+
+// NewTelegramQueue returns a queue which sends telegrams with client and
+// clientKey, restoring any telegrams saved at path.
+func NewTelegramQueue(client *Client, clientKey, path string) (*TelegramQueue, error) {
+	q := &TelegramQueue{
+		Client:    client,
+		ClientKey: clientKey,
+		Path:      path,
+		wake:      make(chan struct{}, 1),
+	}
+	if path == "" {
+		return q, nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return q, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(data, &q.state)
+	if err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// Enqueue adds a telegram to the end of the queue. If the queue cannot be
+// saved, the telegram is not queued and the error is returned.
+func (q *TelegramQueue) Enqueue(tg Telegram, to string) error {
+	return q.EnqueueCampaign("", tg, to)
+}
+
+// EnqueueCampaign is like Enqueue but records the recruitment campaign the
+// telegram belongs to.
+func (q *TelegramQueue) EnqueueCampaign(campaign string, tg Telegram, to string) error {
+	q.mu.Lock()
+	q.state.Pending = append(q.state.Pending, QueuedTelegram{Telegram: tg, To: to, Campaign: campaign})
+	err := q.saveLocked()
+	if err != nil {
+		q.state.Pending = q.state.Pending[:len(q.state.Pending)-1]
+	}
+	q.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	q.signal()
+	return nil
+}
+
+// Len returns the number of telegrams waiting to be sent.
+func (q *TelegramQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.state.Pending)
+}
+
+func (q *TelegramQueue) signal() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// next returns the index of the next telegram which may be sent at now, or
+// how long to wait until one may be sent.
+func (q *TelegramQueue) next(now time.Time) (int, time.Duration) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.state.Pending) == 0 {
+		return -1, 0
+	}
+	wait := q.state.LastSent.Add(TelegramInterval).Sub(now)
+	recruitmentWait := q.state.LastRecruitment.Add(RecruitmentTelegramInterval).Sub(now)
+	if retryWait := q.retryAt.Sub(now); retryWait > wait {
+		wait = retryWait
+	}
+	if recruitmentWait < wait {
+		recruitmentWait = wait
+	}
+	for i, t := range q.state.Pending {
+		if !t.Telegram.Recruitment {
+			if wait <= 0 {
+				return i, 0
+			}
+			return -1, wait
+		}
+		if recruitmentWait <= 0 {
+			return i, 0
+		}
+	}
+	return -1, recruitmentWait
+}
+
+// Run sends queued telegrams until ctx is done. It only returns the error
+// from ctx.
+func (q *TelegramQueue) Run(ctx context.Context) error {
+	for {
+		i, wait := q.next(time.Now())
+		if i < 0 {
+			var timer *time.Timer
+			var expired <-chan time.Time
+			if wait > 0 {
+				timer = time.NewTimer(wait)
+				expired = timer.C
+			}
+			select {
+			case <-ctx.Done():
+			case <-q.wake:
+			case <-expired:
+			}
+			if timer != nil {
+				timer.Stop()
+			}
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			continue
+		}
+		err := q.send(ctx, i)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil && q.OnSaveError != nil {
+			q.OnSaveError(err)
+		}
+	}
+}
+
+// send sends the telegram at index i and removes it from the queue, unless
+// it failed because of a transient error, in which case sending is retried
+// after a delay. It returns the error from saving the queue.
+func (q *TelegramQueue) send(ctx context.Context, i int) error {
+	q.mu.Lock()
+	t := q.state.Pending[i]
+	q.mu.Unlock()
+	var skip bool
+	var err error
+	if q.Skip != nil {
+		skip, err = q.Skip(ctx, t)
+	}
+	if ctx.Err() != nil {
+		return nil
+	}
+	if skip {
+		q.mu.Lock()
+		q.state.Pending = append(q.state.Pending[:i:i], q.state.Pending[i+1:]...)
+		saveErr := q.saveLocked()
+		q.mu.Unlock()
+		return saveErr
+	}
+	attempted := err == nil
+	if attempted {
+		err = q.Client.SendTelegramContext(ctx, q.ClientKey, t.Telegram, t.To)
+		if ctx.Err() != nil {
+			return nil
+		}
+	}
+	q.mu.Lock()
+	now := time.Now()
+	if attempted {
+		q.state.LastSent = now
+		if t.Telegram.Recruitment {
+			q.state.LastRecruitment = now
+		}
+	}
+	if err == nil || attempted && TelegramRejected(err) {
+		q.state.Pending = append(q.state.Pending[:i:i], q.state.Pending[i+1:]...)
+		q.failures = 0
+		q.retryAt = time.Time{}
+	} else {
+		q.retryAt = now.Add(telegramRetryDelay(err, q.failures))
+		q.failures++
+	}
+	saveErr := q.saveLocked()
+	q.mu.Unlock()
+	if err != nil && attempted && q.OnError != nil {
+		q.OnError(t, err)
+	} else if err == nil && q.OnSent != nil {
+		q.OnSent(t)
+	}
+	return saveErr
+}
+
+// telegramRetryDelay returns how long to wait after a transient error, given
+// the number of consecutive failures before it.
+func telegramRetryDelay(err error, failures int) time.Duration {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+		return apiErr.RetryAfter
+	}
+	delay := TelegramRetryDelay
+	for ; failures > 0 && delay < MaxTelegramRetryDelay; failures-- {
+		delay *= 2
+	}
+	if delay > MaxTelegramRetryDelay {
+		delay = MaxTelegramRetryDelay
+	}
+	return delay
+}
+
+// saveLocked writes the queue to Path, replacing the previous file
+// atomically so that a crash cannot lose the whole queue.
+func (q *TelegramQueue) saveLocked() error {
+	if q.Path == "" {
+		return nil
+	}
+	data, err := json.Marshal(q.state)
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(q.Path, data)
+}
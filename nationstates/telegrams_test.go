@@ -0,0 +1,221 @@
+package nationstates
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSendTelegram(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("a") != "sendTG" || q.Get("client") != "client" || q.Get("tgid") != "1" || q.Get("key") != "secret" {
+			t.Errorf("got query %s", r.URL.RawQuery)
+		}
+		if q.Get("to") == "testlandia" {
+			w.Write([]byte("queued\n"))
+		} else {
+			w.Write([]byte("Client not registered for API."))
+		}
+	}))
+	defer server.Close()
+	c := NewClient(WithBaseURL(server.URL))
+	tg := Telegram{ID: "1", SecretKey: "secret"}
+	if err := c.SendTelegram("client", tg, "testlandia"); err != nil {
+		t.Fatal(err)
+	}
+	var tgErr *TelegramError
+	if err := c.SendTelegram("client", tg, "other"); !errors.As(err, &tgErr) {
+		t.Fatalf("got error %v, wanted *TelegramError", err)
+	}
+}
+
+func TestSendTelegramWithoutCredentials(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Password") != "" || r.Header.Get("X-Autologin") != "" || r.Header.Get("X-Pin") != "" {
+			t.Errorf("got credentials in headers %v, wanted none", r.Header)
+		}
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+	creds := Credentials{Autologin: "autologin", Pin: "12345"}
+	c := NewClient(WithBaseURL(server.URL), WithPassword("hunter2"), WithCredentials(creds))
+	err := c.SendTelegram("client", Telegram{ID: "1", SecretKey: "secret"}, "testlandia")
+	if !errors.Is(err, ErrForbidden) {
+		t.Fatalf("got error %v, wanted ErrForbidden", err)
+	}
+	if got := c.Credentials(); got != creds {
+		t.Fatalf("got credentials %+v after 403, wanted them kept as %+v", got, creds)
+	}
+}
+
+func TestTelegramQueueNext(t *testing.T) {
+	now := time.Now()
+	q, err := NewTelegramQueue(nil, "client", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if i, _ := q.next(now); i != -1 {
+		t.Fatalf("got index %d for empty queue, wanted -1", i)
+	}
+	q.Enqueue(Telegram{ID: "1", Recruitment: true}, "a")
+	q.Enqueue(Telegram{ID: "2"}, "b")
+	if i, _ := q.next(now); i != 0 {
+		t.Fatalf("got index %d, wanted 0", i)
+	}
+	// a recruitment telegram was just sent, so the welcome telegram goes
+	// first once the shorter limit has passed
+	q.state.LastSent = now
+	q.state.LastRecruitment = now
+	if i, wait := q.next(now); i != -1 || wait != TelegramInterval {
+		t.Fatalf("got index %d and wait %s, wanted -1 and %s", i, wait, TelegramInterval)
+	}
+	if i, _ := q.next(now.Add(TelegramInterval)); i != 1 {
+		t.Fatalf("got index %d, wanted 1", i)
+	}
+	q.state.Pending = q.state.Pending[:1]
+	if i, wait := q.next(now.Add(TelegramInterval)); i != -1 || wait != RecruitmentTelegramInterval-TelegramInterval {
+		t.Fatalf("got index %d and wait %s", i, wait)
+	}
+}
+
+func TestTelegramQueuePersistence(t *testing.T) {
+	var sent []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent = append(sent, r.URL.Query().Get("to"))
+		w.Write([]byte("queued"))
+	}))
+	defer server.Close()
+	path := filepath.Join(t.TempDir(), "queue.json")
+	q, err := NewTelegramQueue(nil, "client", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q.Enqueue(Telegram{ID: "1"}, "testlandia")
+	q.Enqueue(Telegram{ID: "1"}, "other")
+
+	q, err = NewTelegramQueue(NewClient(WithBaseURL(server.URL)), "client", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.Len() != 2 {
+		t.Fatalf("got %d queued telegrams after restart, wanted 2", q.Len())
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	q.OnSent = func(QueuedTelegram) { cancel() }
+	if err := q.Run(ctx); err != context.Canceled {
+		t.Fatalf("got error %v, wanted %v", err, context.Canceled)
+	}
+	if len(sent) != 1 || sent[0] != "testlandia" {
+		t.Fatalf("got sent %v, wanted [testlandia]", sent)
+	}
+
+	q, err = NewTelegramQueue(nil, "client", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.Len() != 1 {
+		t.Fatalf("got %d queued telegrams after send, wanted 1", q.Len())
+	}
+	if i, wait := q.next(time.Now()); i != -1 || wait <= 0 {
+		t.Fatalf("got index %d and wait %s after restart, wanted rate limit to persist", i, wait)
+	}
+}
+
+func TestTelegramQueueRetry(t *testing.T) {
+	status := http.StatusInternalServerError
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+	q, err := NewTelegramQueue(NewClient(WithBaseURL(server.URL)), "client", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var errs []error
+	q.OnError = func(_ QueuedTelegram, err error) { errs = append(errs, err) }
+	q.Enqueue(Telegram{ID: "1"}, "testlandia")
+
+	q.send(context.Background(), 0)
+	if q.Len() != 1 {
+		t.Fatalf("got %d queued telegrams after server error, wanted 1", q.Len())
+	}
+	if _, wait := q.next(time.Now()); wait < TelegramRetryDelay-time.Second {
+		t.Fatalf("got wait %s after server error, wanted about %s", wait, TelegramRetryDelay)
+	}
+	q.send(context.Background(), 0)
+	if _, wait := q.next(time.Now()); wait < 2*TelegramRetryDelay-time.Second {
+		t.Fatalf("got wait %s after second server error, wanted about %s", wait, 2*TelegramRetryDelay)
+	}
+
+	status = http.StatusBadRequest
+	q.send(context.Background(), 0)
+	if q.Len() != 0 {
+		t.Fatalf("got %d queued telegrams after bad request, wanted 0", q.Len())
+	}
+	if len(errs) != 3 || !TelegramRejected(errs[2]) || TelegramRejected(errs[0]) {
+		t.Fatalf("got errors %v", errs)
+	}
+}
+
+func TestTelegramRejected(t *testing.T) {
+	cases := []struct {
+		err  error
+		want bool
+	}{
+		{&TelegramError{To: "testlandia", Message: "Client not registered for API."}, true},
+		{&APIError{StatusCode: http.StatusBadRequest}, true},
+		{&APIError{StatusCode: http.StatusTooManyRequests}, false},
+		{&APIError{StatusCode: http.StatusBadGateway}, false},
+		{context.DeadlineExceeded, false},
+	}
+	for _, c := range cases {
+		if got := TelegramRejected(c.err); got != c.want {
+			t.Errorf("got %t for %v, wanted %t", got, c.err, c.want)
+		}
+	}
+}
+
+func TestTelegramQueueSkip(t *testing.T) {
+	var sent []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent = append(sent, r.URL.Query().Get("to"))
+		w.Write([]byte("queued"))
+	}))
+	defer server.Close()
+	q, err := NewTelegramQueue(NewClient(WithBaseURL(server.URL)), "client", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkErr := errors.New("lookup failed")
+	q.Skip = func(_ context.Context, t QueuedTelegram) (bool, error) {
+		if checkErr != nil {
+			return false, checkErr
+		}
+		return t.To == "moved", nil
+	}
+	q.EnqueueCampaign("feeders", Telegram{ID: "1"}, "moved")
+	q.EnqueueCampaign("feeders", Telegram{ID: "1"}, "testlandia")
+
+	q.send(context.Background(), 0)
+	if q.Len() != 2 || len(sent) != 0 {
+		t.Fatalf("got %d queued and sent %v after a failed check, wanted 2 queued and none sent", q.Len(), sent)
+	}
+	if _, wait := q.next(time.Now()); wait < TelegramRetryDelay-time.Second {
+		t.Fatalf("got wait %s after a failed check, wanted about %s", wait, TelegramRetryDelay)
+	}
+
+	checkErr = nil
+	q.send(context.Background(), 0)
+	if q.Len() != 1 || len(sent) != 0 {
+		t.Fatalf("got %d queued and sent %v after skipping, wanted 1 queued and none sent", q.Len(), sent)
+	}
+	q.send(context.Background(), 0)
+	if q.Len() != 0 || len(sent) != 1 || sent[0] != "testlandia" {
+		t.Fatalf("got %d queued and sent %v, wanted [testlandia] sent", q.Len(), sent)
+	}
+}
@@ -43,6 +43,7 @@ const (
 	CensusCorruption                      = 51
 	CensusCrime                           = 77
 	CensusCulture                         = 55
+	CensusDead                            = 83
 	CensusDeathRate                       = 5
 	CensusDefenseForces                   = 46
 	CensusEcoFriendliness                 = 7
@@ -50,6 +51,7 @@ const (
 	CensusEconomicOutput                  = 76
 	CensusEmployment                      = 56
 	CensusEnvironmentalBeauty             = 63
+	CensusFoodQuality                     = 88
 	CensusForeignAid                      = 78
 	CensusFreedomFromTaxation             = 50
 	CensusGovernmentSize                  = 27
@@ -84,6 +86,8 @@ const (
 	CensusNudity                          = 9
 	CensusObesity                         = 61
 	CensusPacifism                        = 47
+	CensusPatriotism                      = 87
+	CensusPercentageZombies               = 84
 	CensusPoliticalApathy                 = 38
 	CensusPrimitiveness                   = 69
 	CensusPublicEducation                 = 75
@@ -99,6 +103,7 @@ const (
 	CensusSectorManufacturing             = 26
 	CensusSecularism                      = 62
 	CensusSocialConservatism              = 8
+	CensusSurvivors                       = 81
 	CensusTaxation                        = 49
 	CensusTourism                         = 58
 	CensusWealthGaps                      = 4
@@ -107,119 +112,226 @@ const (
 	CensusWelfare                         = 28
 	CensusWorldAssemblyEndorsements       = 66
 	CensusYouthRebelliousness             = 54
+	CensusZombies                         = 82
 	CensusAlphabetical                    = 254
 )
 
-var CensusLabels = map[int]string{
-	CensusCivilRights:                     "Civil Rights",
-	CensusEconomy:                         "Economy",
-	CensusPoliticalFreedom:                "Political Freedom",
-	CensusPopulation:                      "Population",
-	CensusAuthoritarianism:                "Authoritarianism",
-	CensusAverageDisposableIncome:         "Average Disposable Income",
-	CensusAverageIncome:                   "Average Income",
-	CensusAverageIncomeofPoor:             "Average Income of Poor",
-	CensusAverageIncomeofRich:             "Average Income of Rich",
-	CensusAverageness:                     "Averageness",
-	CensusBlackMarket:                     "Black Market",
-	CensusBusinessSubsidization:           "Business Subsidization",
-	CensusCharmlessness:                   "Charmlessness",
-	CensusCheerfulness:                    "Cheerfulness",
-	CensusCompassion:                      "Compassion",
-	CensusCompliance:                      "Compliance",
-	CensusCorruption:                      "Corruption",
-	CensusCrime:                           "Crime",
-	CensusCulture:                         "Culture",
-	CensusDeathRate:                       "Death Rate",
-	CensusDefenseForces:                   "Defense Forces",
-	CensusEcoFriendliness:                 "Eco-Friendliness",
-	CensusEconomicFreedom:                 "Economic Freedom",
-	CensusEconomicOutput:                  "Economic Output",
-	CensusEmployment:                      "Employment",
-	CensusEnvironmentalBeauty:             "Environmental Beauty",
-	CensusForeignAid:                      "Foreign Aid",
-	CensusFreedomFromTaxation:             "Freedom From Taxation",
-	CensusGovernmentSize:                  "Government Size",
-	CensusHealth:                          "Health",
-	CensusHumanDevelopmentIndex:           "Human Development Index",
-	CensusIdeologicalRadicality:           "Ideological Radicality",
-	CensusIgnorance:                       "Ignorance",
-	CensusInclusiveness:                   "Inclusiveness",
-	CensusIncomeEquality:                  "Income Equality",
-	CensusIndustryArmsManufacturing:       "Industry: Arms Manufacturing",
-	CensusIndustryAutomobileManufacturing: "Industry: Automobile Manufacturing",
-	CensusIndustryBasketWeaving:           "Industry: Basket Weaving",
-	CensusIndustryBeverageSales:           "Industry: Beverage Sales",
-	CensusIndustryBookPublishing:          "Industry: Book Publishing",
-	CensusIndustryCheeseExports:           "Industry: Cheese Exports",
-	CensusIndustryFurnitureRestoration:    "Industry: Furniture Restoration",
-	CensusIndustryGambling:                "Industry: Gambling",
-	CensusIndustryInformationTechnology:   "Industry: Information Technology",
-	CensusIndustryInsurance:               "Industry: Insurance",
-	CensusIndustryMining:                  "Industry: Mining",
-	CensusIndustryPizzaDelivery:           "Industry: Pizza Delivery",
-	CensusIndustryRetail:                  "Industry: Retail",
-	CensusIndustryTimberWoodchipping:      "Industry: Timber Woodchipping",
-	CensusIndustryTroutFishing:            "Industry: Trout Fishing",
-	CensusInfluence:                       "Influence",
-	CensusIntegrity:                       "Integrity",
-	CensusIntelligence:                    "Intelligence",
-	CensusInternationalArtwork:            "International Artwork",
-	CensusLawEnforcement:                  "Law Enforcement",
-	CensusLifespan:                        "Lifespan",
-	CensusNiceness:                        "Niceness",
-	CensusNudity:                          "Nudity",
-	CensusObesity:                         "Obesity",
-	CensusPacifism:                        "Pacifism",
-	CensusPoliticalApathy:                 "Political Apathy",
-	CensusPrimitiveness:                   "Primitiveness",
-	CensusPublicEducation:                 "Public Education",
-	CensusPublicHealthcare:                "Public Healthcare",
-	CensusPublicTransport:                 "Public Transport",
-	CensusRecreationalDrugUse:             "Recreational Drug Use",
-	CensusReligiousness:                   "Religiousness",
-	CensusResidency:                       "Residency",
-	CensusRudeness:                        "Rudeness",
-	CensusSafety:                          "Safety",
-	CensusScientificAdvancement:           "Scientific Advancement",
-	CensusSectorAgriculture:               "Sector: Agriculture",
-	CensusSectorManufacturing:             "Next: Sector: Manufacturing",
-	CensusSecularism:                      "Secularism",
-	CensusSocialConservatism:              "Social Conservatism",
-	CensusTaxation:                        "Taxation",
-	CensusTourism:                         "Tourism",
-	CensusWealthGaps:                      "Wealth Gaps",
-	CensusWeaponization:                   "Weaponization",
-	CensusWeather:                         "Weather",
-	CensusWelfare:                         "Welfare",
-	CensusWorldAssemblyEndorsements:       "World Assembly Endorsements",
-	CensusYouthRebelliousness:             "Youth Rebelliousness",
-	CensusAlphabetical:                    "Alphabetical",
-}
+const (
+	WAStatusMember    = "WA Member"
+	WAStatusDelegate  = "WA Delegate"
+	WAStatusNonMember = "Non-member"
+)
 
+// Nation holds the shards returned by the Nation API. Only the fields for the
+// requested shards are filled in.
 type Nation struct {
-	XMLName      xml.Name     `xml:"NATION"`
-	ID           string       `xml:"id,attr"`
-	Consequences Consequences `xml:"ISSUE"`
-	Issues       []Issue      `xml:"ISSUES>ISSUE"`
-	Notices      []Notice     `xml:"NOTICES>NOTICE"`
+	XMLName        xml.Name      `xml:"NATION"`
+	ID             string        `xml:"id,attr"`
+	Name           string        `xml:"NAME"`
+	FullName       string        `xml:"FULLNAME"`
+	Type           string        `xml:"TYPE"`
+	Motto          string        `xml:"MOTTO"`
+	Category       string        `xml:"CATEGORY"`
+	WAStatus       string        `xml:"UNSTATUS"`
+	Endorsements   CommaList     `xml:"ENDORSEMENTS"`
+	IssuesAnswered int           `xml:"ISSUES_ANSWERED"`
+	Freedom        Freedom       `xml:"FREEDOM"`
+	FreedomScores  FreedomScores `xml:"FREEDOMSCORES"`
+	Region         string        `xml:"REGION"`
+	Population     int           `xml:"POPULATION"`
+	Tax            float64       `xml:"TAX"`
+	Animal         string        `xml:"ANIMAL"`
+	AnimalTrait    string        `xml:"ANIMALTRAIT"`
+	Currency       string        `xml:"CURRENCY"`
+	Demonym        string        `xml:"DEMONYM"`
+	Demonym2       string        `xml:"DEMONYM2"`
+	Demonym2Plural string        `xml:"DEMONYM2PLURAL"`
+	Flag           string        `xml:"FLAG"`
+	Banner         string        `xml:"BANNER"`
+	Banners        []string      `xml:"BANNERS>BANNER"`
+	MajorIndustry  string        `xml:"MAJORINDUSTRY"`
+	GovtPriority   string        `xml:"GOVTPRIORITY"`
+	Govt           Govt          `xml:"GOVT"`
+	GovtDesc       string        `xml:"GOVTDESC"`
+	IndustryDesc   string        `xml:"INDUSTRYDESC"`
+	Crime          string        `xml:"CRIME"`
+	Sensibilities  string        `xml:"SENSIBILITIES"`
+	Leader         string        `xml:"LEADER"`
+	Capital        string        `xml:"CAPITAL"`
+	Religion       string        `xml:"RELIGION"`
+	Founded        string        `xml:"FOUNDED"`
+	FoundedTime    int           `xml:"FOUNDEDTIME"`
+	FirstLogin     int           `xml:"FIRSTLOGIN"`
+	LastLogin      int           `xml:"LASTLOGIN"`
+	LastActivity   string        `xml:"LASTACTIVITY"`
+	Influence      string        `xml:"INFLUENCE"`
+	PublicSector   float64       `xml:"PUBLICSECTOR"`
+	Sectors        Sectors       `xml:"SECTORS"`
+	Deaths         []Cause       `xml:"DEATHS>CAUSE"`
+	Policies       []Policy      `xml:"POLICIES>POLICY"`
+	Notables       []string      `xml:"NOTABLES>NOTABLE"`
+	Admirables     []string      `xml:"ADMIRABLES>ADMIRABLE"`
+	Legislation    []string      `xml:"LEGISLATION>LAW"`
+	GDP            int64         `xml:"GDP"`
+	Income         int           `xml:"INCOME"`
+	Poorest        int           `xml:"POOREST"`
+	Richest        int           `xml:"RICHEST"`
+	Factbooks      int           `xml:"FACTBOOKS"`
+	Dispatches     int           `xml:"DISPATCHES"`
+	DBID           int           `xml:"DBID"`
+	GAVote         string        `xml:"GAVOTE"`
+	SCVote         string        `xml:"SCVOTE"`
+	WABadges       []WABadge     `xml:"WABADGES>WABADGE"`
+	Happenings     []Event       `xml:"HAPPENINGS>EVENT"`
+	Census         []CensusScale `xml:"CENSUS>SCALE"`
+	NextIssueTime  int           `xml:"NEXTISSUETIME"`
+	TGCanRecruit   bool          `xml:"TGCANRECRUIT"`
+	TGCanCampaign  bool          `xml:"TGCANCAMPAIGN"`
+	Consequences   Consequences  `xml:"ISSUE"`
+	Issues         []Issue       `xml:"ISSUES>ISSUE"`
+	Notices        []Notice      `xml:"NOTICES>NOTICE"`
+}
+
+// Freedom holds the descriptions of a nation's freedoms, such as "Excellent".
+type Freedom struct {
+	CivilRights      string `xml:"CIVILRIGHTS"`
+	Economy          string `xml:"ECONOMY"`
+	PoliticalFreedom string `xml:"POLITICALFREEDOM"`
+}
+
+// FreedomScores holds a nation's freedom scores out of 100.
+type FreedomScores struct {
+	CivilRights      int `xml:"CIVILRIGHTS"`
+	Economy          int `xml:"ECONOMY"`
+	PoliticalFreedom int `xml:"POLITICALFREEDOM"`
+}
+
+// Govt holds the percentage of government spending in each area.
+type Govt struct {
+	Administration   float64 `xml:"ADMINISTRATION"`
+	Defence          float64 `xml:"DEFENCE"`
+	Education        float64 `xml:"EDUCATION"`
+	Environment      float64 `xml:"ENVIRONMENT"`
+	Healthcare       float64 `xml:"HEALTHCARE"`
+	Commerce         float64 `xml:"COMMERCE"`
+	InternationalAid float64 `xml:"INTERNATIONALAID"`
+	LawAndOrder      float64 `xml:"LAWANDORDER"`
+	PublicTransport  float64 `xml:"PUBLICTRANSPORT"`
+	SocialEquality   float64 `xml:"SOCIALEQUALITY"`
+	Spirituality     float64 `xml:"SPIRITUALITY"`
+	Welfare          float64 `xml:"WELFARE"`
+}
+
+// Sectors holds the percentage of the economy in each sector.
+type Sectors struct {
+	BlackMarket float64 `xml:"BLACKMARKET"`
+	Government  float64 `xml:"GOVERNMENT"`
+	Industry    float64 `xml:"INDUSTRY"`
+	Public      float64 `xml:"PUBLIC"`
+}
+
+// Cause is a cause of death and the percentage of deaths it accounts for.
+type Cause struct {
+	Type    string  `xml:"type,attr"`
+	Percent float64 `xml:",chardata"`
+}
+
+// Policy is a policy in effect in a nation.
+type Policy struct {
+	Name     string `xml:"NAME"`
+	Picture  string `xml:"PIC"`
+	Category string `xml:"CAT"`
+	Desc     string `xml:"DESC"`
 }
 
+// WABadge is a commendation, condemnation or liberation by the Security Council.
+type WABadge struct {
+	Type       string `xml:"type,attr"`
+	Resolution int    `xml:",chardata"`
+}
+
+// Issue is an issue awaiting an answer. Options are in the order NationStates
+// displays them, and their IDs, which AnswerIssue expects, are not
+// necessarily consecutive.
 type Issue struct {
 	ID      int      `xml:"id,attr"`
 	Title   string   `xml:"TITLE"`
 	Text    string   `xml:"TEXT"`
+	Author  string   `xml:"AUTHOR"`
+	Editor  string   `xml:"EDITOR"`
+	Pic1    string   `xml:"PIC1"`
+	Pic2    string   `xml:"PIC2"`
 	Options []Option `xml:"OPTION"`
 }
 
+// Option returns the option with the given ID.
+func (i Issue) Option(id int) (Option, bool) {
+	for _, option := range i.Options {
+		if option.ID == id {
+			return option, true
+		}
+	}
+	return Option{}, false
+}
+
+// PictureURLs returns the URLs of the illustrations for the issue.
+func (i Issue) PictureURLs() []string {
+	var urls []string
+	for _, pic := range []string{i.Pic1, i.Pic2} {
+		if pic != "" {
+			urls = append(urls, PictureURL(pic))
+		}
+	}
+	return urls
+}
+
+// Consequences is the result of answering an issue.
 type Consequences struct {
-	Desc      string   `xml:"DESC"`
-	Rankings  []Rank   `xml:"RANKINGS>RANK"`
-	Headlines []string `xml:"HEADLINES>HEADLINE"`
+	IssueID int  `xml:"id,attr"`
+	Choice  int  `xml:"choice,attr"`
+	OK      bool `xml:"OK"`
+
+	Desc              string             `xml:"DESC"`
+	Rankings          []Rank             `xml:"RANKINGS>RANK"`
+	Unlocks           []string           `xml:"UNLOCKS>BANNER"`
+	Reclassifications []Reclassification `xml:"RECLASSIFICATIONS>RECLASSIFY"`
+	NewPolicies       []Policy           `xml:"NEW_POLICIES>POLICY"`
+	RemovedPolicies   []Policy           `xml:"REMOVED_POLICIES>POLICY"`
+	Headlines         []string           `xml:"HEADLINES>HEADLINE"`
 
 	Error string `xml:"ERROR"`
 }
 
+const (
+	ReclassifyCivilRights      = "0"
+	ReclassifyEconomy          = "1"
+	ReclassifyPoliticalFreedom = "2"
+	ReclassifyGovt             = "govt"
+)
+
+// Reclassification is a change in a nation's government category or in the
+// description of one of its freedoms.
+type Reclassification struct {
+	Type string `xml:"type,attr"`
+	From string `xml:"FROM"`
+	To   string `xml:"TO"`
+}
+
+// Label returns a human readable name for what was reclassified.
+func (r Reclassification) Label() string {
+	switch r.Type {
+	case ReclassifyCivilRights:
+		return "Civil Rights"
+	case ReclassifyEconomy:
+		return "Economy"
+	case ReclassifyPoliticalFreedom:
+		return "Political Freedom"
+	case ReclassifyGovt:
+		return "Category"
+	}
+	return r.Type
+}
+
 type Rank struct {
 	ID      int     `xml:"id,attr"`
 	Score   float32 `xml:"SCORE"`
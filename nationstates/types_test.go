@@ -23,6 +23,35 @@ func TestUnmarshalConsequences(t *testing.T) {
         <PCHANGE>0.333175</PCHANGE>
       </RANK>
     </RANKINGS>
+    <UNLOCKS>
+      <BANNER>b22</BANNER>
+    </UNLOCKS>
+    <RECLASSIFICATIONS>
+      <RECLASSIFY type="govt">
+        <FROM>Inoffensive Centrist Democracy</FROM>
+        <TO>Capitalizt</TO>
+      </RECLASSIFY>
+      <RECLASSIFY type="1">
+        <FROM>Strong</FROM>
+        <TO>Very Strong</TO>
+      </RECLASSIFY>
+    </RECLASSIFICATIONS>
+    <NEW_POLICIES>
+      <POLICY>
+        <NAME>No Minimum Wage</NAME>
+        <PIC>t39</PIC>
+        <CAT>Economy</CAT>
+        <DESC>Employers may pay workers as little as they like.</DESC>
+      </POLICY>
+    </NEW_POLICIES>
+    <REMOVED_POLICIES>
+      <POLICY>
+        <NAME>Minimum Wage</NAME>
+        <PIC>t38</PIC>
+        <CAT>Economy</CAT>
+        <DESC>Workers are guaranteed a minimum wage.</DESC>
+      </POLICY>
+    </REMOVED_POLICIES>
     <HEADLINES>
       <HEADLINE>Retailers Welcome Tax Cut</HEADLINE>
       <HEADLINE>Aristocrats Welcome Rising Income Inequality</HEADLINE>
@@ -38,10 +67,24 @@ func TestUnmarshalConsequences(t *testing.T) {
 		t.Fatal(err)
 	}
 	want := Consequences{
-		Desc: "companies balk at paying their workers",
+		IssueID: 369,
+		Choice:  1,
+		OK:      true,
+		Desc:    "companies balk at paying their workers",
 		Rankings: []Rank{
-			{Score: 6.29, Change: 1.15, PChange: 22.37354},
-			{Score: 21.08, Change: 0.07, PChange: 0.333175},
+			{ID: 4, Score: 6.29, Change: 1.15, PChange: 22.37354},
+			{ID: 5, Score: 21.08, Change: 0.07, PChange: 0.333175},
+		},
+		Unlocks: []string{"b22"},
+		Reclassifications: []Reclassification{
+			{Type: ReclassifyGovt, From: "Inoffensive Centrist Democracy", To: "Capitalizt"},
+			{Type: ReclassifyEconomy, From: "Strong", To: "Very Strong"},
+		},
+		NewPolicies: []Policy{
+			{Name: "No Minimum Wage", Picture: "t39", Category: "Economy", Desc: "Employers may pay workers as little as they like."},
+		},
+		RemovedPolicies: []Policy{
+			{Name: "Minimum Wage", Picture: "t38", Category: "Economy", Desc: "Workers are guaranteed a minimum wage."},
 		},
 		Headlines: []string{
 			"Retailers Welcome Tax Cut",
@@ -54,3 +97,132 @@ func TestUnmarshalConsequences(t *testing.T) {
 		t.Fatalf("got %v, wanted %v", got, want)
 	}
 }
+
+func TestUnmarshalNation(t *testing.T) {
+	s := `<NATION id="testlandia">
+  <NAME>Testlandia</NAME>
+  <FULLNAME>The Hive Mind of Testlandia</FULLNAME>
+  <MOTTO>It works on my machine</MOTTO>
+  <CATEGORY>Democratic Socialists</CATEGORY>
+  <UNSTATUS>WA Delegate</UNSTATUS>
+  <ENDORSEMENTS>wilbert,maxtopia</ENDORSEMENTS>
+  <FREEDOM>
+    <CIVILRIGHTS>Excellent</CIVILRIGHTS>
+    <ECONOMY>Strong</ECONOMY>
+    <POLITICALFREEDOM>Superb</POLITICALFREEDOM>
+  </FREEDOM>
+  <REGION>Testregionia</REGION>
+  <POPULATION>7341</POPULATION>
+  <TAX>23.5</TAX>
+  <GOVT>
+    <ADMINISTRATION>5.2</ADMINISTRATION>
+    <DEFENCE>10.1</DEFENCE>
+  </GOVT>
+  <DEATHS>
+    <CAUSE type="Old Age">78.3</CAUSE>
+  </DEATHS>
+  <POLICIES>
+    <POLICY>
+      <NAME>Autoless Streets</NAME>
+      <PIC>cars</PIC>
+      <CAT>Transport</CAT>
+      <DESC>Private vehicles are banned.</DESC>
+    </POLICY>
+  </POLICIES>
+  <SECTORS>
+    <BLACKMARKET>1.5</BLACKMARKET>
+    <GOVERNMENT>40</GOVERNMENT>
+    <INDUSTRY>28.5</INDUSTRY>
+    <PUBLIC>30</PUBLIC>
+  </SECTORS>
+  <LASTACTIVITY>2 hours ago</LASTACTIVITY>
+  <FOUNDEDTIME>1581740400</FOUNDEDTIME>
+  <WABADGES>
+    <WABADGE type="commend">123</WABADGE>
+  </WABADGES>
+  <TGCANRECRUIT>1</TGCANRECRUIT>
+</NATION>
+`
+	var n Nation
+	err := xml.Unmarshal([]byte(s), &n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Nation{
+		XMLName:      xml.Name{Local: "NATION"},
+		ID:           "testlandia",
+		Name:         "Testlandia",
+		FullName:     "The Hive Mind of Testlandia",
+		Motto:        "It works on my machine",
+		Category:     "Democratic Socialists",
+		WAStatus:     WAStatusDelegate,
+		Endorsements: CommaList{"wilbert", "maxtopia"},
+		Freedom: Freedom{
+			CivilRights:      "Excellent",
+			Economy:          "Strong",
+			PoliticalFreedom: "Superb",
+		},
+		Region:       "Testregionia",
+		Population:   7341,
+		Tax:          23.5,
+		Govt:         Govt{Administration: 5.2, Defence: 10.1},
+		Deaths:       []Cause{{Type: "Old Age", Percent: 78.3}},
+		Policies:     []Policy{{Name: "Autoless Streets", Picture: "cars", Category: "Transport", Desc: "Private vehicles are banned."}},
+		Sectors:      Sectors{BlackMarket: 1.5, Government: 40, Industry: 28.5, Public: 30},
+		LastActivity: "2 hours ago",
+		FoundedTime:  1581740400,
+		WABadges:     []WABadge{{Type: "commend", Resolution: 123}},
+		TGCanRecruit: true,
+	}
+	if !reflect.DeepEqual(n, want) {
+		t.Fatalf("got %+v, wanted %+v", n, want)
+	}
+}
+
+func TestUnmarshalIssues(t *testing.T) {
+	s := `<NATION id="testlandia">
+  <ISSUES>
+    <ISSUE id="1234">
+      <TITLE>Testing, Testing</TITLE>
+      <TEXT>Should tests be mandatory?</TEXT>
+      <AUTHOR>wilbert</AUTHOR>
+      <EDITOR>maxtopia</EDITOR>
+      <PIC1>t23</PIC1>
+      <PIC2>c4</PIC2>
+      <OPTION id="0">Yes.</OPTION>
+      <OPTION id="2">No.</OPTION>
+    </ISSUE>
+  </ISSUES>
+</NATION>
+`
+	var n Nation
+	err := xml.Unmarshal([]byte(s), &n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Issue{
+		{
+			ID:      1234,
+			Title:   "Testing, Testing",
+			Text:    "Should tests be mandatory?",
+			Author:  "wilbert",
+			Editor:  "maxtopia",
+			Pic1:    "t23",
+			Pic2:    "c4",
+			Options: []Option{{ID: 0, Text: "Yes."}, {ID: 2, Text: "No."}},
+		},
+	}
+	if !reflect.DeepEqual(n.Issues, want) {
+		t.Fatalf("got %+v, wanted %+v", n.Issues, want)
+	}
+	wantURLs := []string{
+		"https://www.nationstates.net/images/dilemmas/t23.jpg",
+		"https://www.nationstates.net/images/dilemmas/c4.jpg",
+	}
+	if urls := n.Issues[0].PictureURLs(); !reflect.DeepEqual(urls, wantURLs) {
+		t.Fatalf("got picture urls %v, wanted %v", urls, wantURLs)
+	}
+	if option, ok := n.Issues[0].Option(2); !ok || option.Text != "No." {
+		t.Fatalf("got option %+v, %t, wanted option 2", option, ok)
+	}
+}
@@ -0,0 +1,43 @@
+package nationstates
+
+import (
+	"context"
+	"net/url"
+)
+
+// VerifyURL returns the page where a player can get a checksum proving that
+// they own a nation. If token is not empty, the checksum is only valid for
+// that token, which stops a checksum given to one site from being reused on
+// another.
+func VerifyURL(token string) string {
+	u := "https://www.nationstates.net/page=verify_login"
+	if token != "" {
+		u += "?token=" + url.QueryEscape(token)
+	}
+	return u
+}
+
+// Verify reports whether checksum proves ownership of nation. token must be
+// the same token that was used to get the checksum from VerifyURL.
+// Verification does not use the client's own credentials.
+func (c *Client) Verify(nation, checksum, token string) (bool, error) {
+	return c.VerifyContext(context.Background(), nation, checksum, token)
+}
+
+// VerifyContext is like Verify but with a context.
+func (c *Client) VerifyContext(ctx context.Context, nation, checksum, token string) (bool, error) {
+	params := url.Values{
+		"a":        {"verify"},
+		"nation":   {nation},
+		"checksum": {checksum},
+	}
+	if token != "" {
+		params.Set("token", token)
+	}
+	var body []byte
+	err := c.doPublic(ctx, params, &body)
+	if err != nil {
+		return false, err
+	}
+	return errorMessage(body) == "1", nil
+}
@@ -0,0 +1,41 @@
+package nationstates
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestVerify(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Password") != "" || r.Header.Get("X-Autologin") != "" {
+			t.Errorf("got credentials in headers %v, wanted none", r.Header)
+		}
+		q := r.URL.Query()
+		if q.Get("a") != "verify" || q.Get("nation") != "testlandia" || q.Get("token") != "token" {
+			t.Errorf("got query %s", r.URL.RawQuery)
+		}
+		if q.Get("checksum") == "good" {
+			w.Write([]byte("1\n"))
+		} else {
+			w.Write([]byte("0\n"))
+		}
+	}))
+	defer server.Close()
+	c := NewClient(WithBaseURL(server.URL), WithPassword("hunter2"), WithCredentials(Credentials{Autologin: "autologin"}))
+	for checksum, want := range map[string]bool{"good": true, "bad": false} {
+		ok, err := c.Verify("testlandia", checksum, "token")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok != want {
+			t.Errorf("checksum %s: got %t, wanted %t", checksum, ok, want)
+		}
+	}
+}
+
+func TestVerifyURL(t *testing.T) {
+	if got, want := VerifyURL("a b"), "https://www.nationstates.net/page=verify_login?token=a+b"; got != want {
+		t.Fatalf("got %q, wanted %q", got, want)
+	}
+}
@@ -0,0 +1,155 @@
+package nationstates
+
+import (
+	"context"
+	"encoding/xml"
+	"strconv"
+)
+
+const (
+	GeneralAssembly = 1
+	SecurityCouncil = 2
+)
+
+// WorldAssembly holds the shards returned by the World Assembly API for a
+// council.
+type WorldAssembly struct {
+	XMLName xml.Name `xml:"WA"`
+	// Council is GeneralAssembly or SecurityCouncil.
+	Council      int       `xml:"council,attr"`
+	NumNations   int       `xml:"NUMNATIONS"`
+	NumDelegates int       `xml:"NUMDELEGATES"`
+	Delegates    CommaList `xml:"DELEGATES"`
+	Members      CommaList `xml:"MEMBERS"`
+	// Happenings are recent World Assembly events, newest first.
+	Happenings []Event    `xml:"HAPPENINGS>EVENT"`
+	Proposals  []Proposal `xml:"PROPOSALS>PROPOSAL"`
+	// Resolution is nil if no resolution is at vote in the council.
+	Resolution *Resolution `xml:"RESOLUTION"`
+	// LastResolution describes the outcome of the last resolution to leave
+	// the floor, in HTML.
+	LastResolution string `xml:"LASTRESOLUTION"`
+}
+
+// Resolution is the resolution currently at vote. The voters, votetrack,
+// dellog and delvotes shards fill in the corresponding fields.
+type Resolution struct {
+	ID         string `xml:"ID"`
+	Name       string `xml:"NAME"`
+	Category   string `xml:"CATEGORY"`
+	Option     string `xml:"OPTION"`
+	Desc       string `xml:"DESC"`
+	ProposedBy string `xml:"PROPOSED_BY"`
+	// Created is when the resolution was proposed and Promoted is when it
+	// reached the floor, as Unix timestamps.
+	Created  int `xml:"CREATED"`
+	Promoted int `xml:"PROMOTED"`
+	// TotalNationsFor and TotalNationsAgainst count the nations voting each
+	// way, while TotalVotesFor and TotalVotesAgainst also count the extra
+	// votes cast by delegates on behalf of their endorsers.
+	TotalNationsFor     int `xml:"TOTAL_NATIONS_FOR"`
+	TotalNationsAgainst int `xml:"TOTAL_NATIONS_AGAINST"`
+	TotalVotesFor       int `xml:"TOTAL_VOTES_FOR"`
+	TotalVotesAgainst   int `xml:"TOTAL_VOTES_AGAINST"`
+	// VotesFor and VotesAgainst are the names of the nations voting each way.
+	VotesFor     []string `xml:"VOTES_FOR>N"`
+	VotesAgainst []string `xml:"VOTES_AGAINST>N"`
+	// VoteTrackFor and VoteTrackAgainst are the vote totals at each hour
+	// since the resolution reached the floor, oldest first.
+	VoteTrackFor     []int `xml:"VOTE_TRACK_FOR>N"`
+	VoteTrackAgainst []int `xml:"VOTE_TRACK_AGAINST>N"`
+	// DelLog lists the votes cast and withdrawn by delegates, oldest first.
+	DelLog          []DelLogEntry `xml:"DELLOG>ENTRY"`
+	DelVotesFor     []DelVote     `xml:"DELVOTES_FOR>DELEGATE"`
+	DelVotesAgainst []DelVote     `xml:"DELVOTES_AGAINST>DELEGATE"`
+}
+
+// VoteTally is the number of votes for and against a resolution at a point in time.
+type VoteTally struct {
+	For     int
+	Against int
+}
+
+// Tallies returns the hourly vote tallies from the votetrack shard, oldest first.
+func (r Resolution) Tallies() []VoteTally {
+	n := len(r.VoteTrackFor)
+	if len(r.VoteTrackAgainst) < n {
+		n = len(r.VoteTrackAgainst)
+	}
+	tallies := make([]VoteTally, n)
+	for i := range tallies {
+		tallies[i] = VoteTally{For: r.VoteTrackFor[i], Against: r.VoteTrackAgainst[i]}
+	}
+	return tallies
+}
+
+const (
+	DelegateVotedFor     = "vote for"
+	DelegateVotedAgainst = "vote against"
+	DelegateWithdrew     = "withdrew its vote"
+)
+
+// DelLogEntry is an entry in the log of votes cast by delegates.
+type DelLogEntry struct {
+	Nation string `xml:"NATION"`
+	// Action is one of the DelegateVoted constants or DelegateWithdrew.
+	Action string `xml:"ACTION"`
+	// Timestamp is when the vote was cast, as a Unix timestamp, and Votes is
+	// the number of votes the delegate carried at the time.
+	Timestamp int `xml:"TIMESTAMP"`
+	Votes     int `xml:"VOTES"`
+}
+
+// DelVote is a delegate's current vote and how many votes it carries.
+type DelVote struct {
+	Nation string `xml:"NATION"`
+	Votes  int    `xml:"VOTES"`
+	// Timestamp is when the vote was cast, as a Unix timestamp.
+	Timestamp int `xml:"TIMESTAMP"`
+}
+
+// Proposal is a proposal awaiting delegate approvals.
+type Proposal struct {
+	ID         string   `xml:"ID"`
+	Name       string   `xml:"NAME"`
+	Category   string   `xml:"CATEGORY"`
+	Option     string   `xml:"OPTION"`
+	ProposedBy string   `xml:"PROPOSED_BY"`
+	Coauthors  []string `xml:"COAUTHOR>N"`
+	Approvals  NameList `xml:"APPROVALS"`
+	// Created is when the proposal was submitted, as a Unix timestamp.
+	Created int `xml:"CREATED"`
+}
+
+// GetWorldAssembly is a generic method for querying the World Assembly API
+// for a council, either GeneralAssembly or SecurityCouncil.
+func (c *Client) GetWorldAssembly(council int, shards []Shard, params ...Param) (WorldAssembly, error) {
+	return c.GetWorldAssemblyContext(context.Background(), council, shards, params...)
+}
+
+// GetWorldAssemblyContext is like GetWorldAssembly but with a context.
+func (c *Client) GetWorldAssemblyContext(ctx context.Context, council int, shards []Shard, params ...Param) (WorldAssembly, error) {
+	q := query(shards, params)
+	q.Set("wa", strconv.Itoa(council))
+	var wa WorldAssembly
+	err := c.doPublic(ctx, q, &wa)
+	return wa, err
+}
+
+// GetResolution is a convenience method for getting the resolution at vote in
+// a council together with its vote tallies. It returns nil if no resolution is at vote.
+func (c *Client) GetResolution(council int) (*Resolution, error) {
+	return c.GetResolutionContext(context.Background(), council)
+}
+
+// GetResolutionContext is like GetResolution but with a context.
+func (c *Client) GetResolutionContext(ctx context.Context, council int) (*Resolution, error) {
+	wa, err := c.GetWorldAssemblyContext(ctx, council, []Shard{ShardResolution, ShardVoteTrack})
+	if err != nil {
+		return nil, err
+	}
+	if wa.Resolution == nil || wa.Resolution.Name == "" {
+		return nil, nil
+	}
+	return wa.Resolution, nil
+}
@@ -0,0 +1,106 @@
+package nationstates
+
+import (
+	"encoding/xml"
+	"reflect"
+	"testing"
+)
+
+func TestUnmarshalWorldAssembly(t *testing.T) {
+	s := `<WA council="1">
+  <RESOLUTION>
+    <CATEGORY>Civil Rights</CATEGORY>
+    <CREATED>1581600000</CREATED>
+    <DESC>The World Assembly, [i]believing[/i] that...</DESC>
+    <ID>testlandia_1581600000</ID>
+    <NAME>Right To Test</NAME>
+    <OPTION>Mild</OPTION>
+    <PROMOTED>1581700000</PROMOTED>
+    <PROPOSED_BY>testlandia</PROPOSED_BY>
+    <TOTAL_NATIONS_AGAINST>300</TOTAL_NATIONS_AGAINST>
+    <TOTAL_NATIONS_FOR>900</TOTAL_NATIONS_FOR>
+    <TOTAL_VOTES_AGAINST>2000</TOTAL_VOTES_AGAINST>
+    <TOTAL_VOTES_FOR>5000</TOTAL_VOTES_FOR>
+    <VOTE_TRACK_FOR><N>10</N><N>2500</N><N>5000</N></VOTE_TRACK_FOR>
+    <VOTE_TRACK_AGAINST><N>3</N><N>1000</N><N>2000</N></VOTE_TRACK_AGAINST>
+    <DELLOG>
+      <ENTRY>
+        <TIMESTAMP>1581700100</TIMESTAMP>
+        <NATION>wilbert</NATION>
+        <ACTION>vote for</ACTION>
+        <VOTES>120</VOTES>
+      </ENTRY>
+    </DELLOG>
+    <DELVOTES_FOR>
+      <DELEGATE>
+        <NATION>wilbert</NATION>
+        <VOTES>120</VOTES>
+        <TIMESTAMP>1581700100</TIMESTAMP>
+      </DELEGATE>
+    </DELVOTES_FOR>
+  </RESOLUTION>
+  <PROPOSALS>
+    <PROPOSAL id="maxtopia_1581650000">
+      <APPROVALS>wilbert:testlandia</APPROVALS>
+      <CATEGORY>Health</CATEGORY>
+      <COAUTHOR><N>wilbert</N></COAUTHOR>
+      <CREATED>1581650000</CREATED>
+      <ID>maxtopia_1581650000</ID>
+      <NAME>Free Tests For All</NAME>
+      <OPTION>Healthcare</OPTION>
+      <PROPOSED_BY>maxtopia</PROPOSED_BY>
+    </PROPOSAL>
+  </PROPOSALS>
+</WA>
+`
+	var got WorldAssembly
+	err := xml.Unmarshal([]byte(s), &got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := WorldAssembly{
+		XMLName: xml.Name{Local: "WA"},
+		Council: GeneralAssembly,
+		Resolution: &Resolution{
+			ID:                  "testlandia_1581600000",
+			Name:                "Right To Test",
+			Category:            "Civil Rights",
+			Option:              "Mild",
+			Desc:                "The World Assembly, [i]believing[/i] that...",
+			ProposedBy:          "testlandia",
+			Created:             1581600000,
+			Promoted:            1581700000,
+			TotalNationsFor:     900,
+			TotalNationsAgainst: 300,
+			TotalVotesFor:       5000,
+			TotalVotesAgainst:   2000,
+			VoteTrackFor:        []int{10, 2500, 5000},
+			VoteTrackAgainst:    []int{3, 1000, 2000},
+			DelLog: []DelLogEntry{
+				{Nation: "wilbert", Action: DelegateVotedFor, Timestamp: 1581700100, Votes: 120},
+			},
+			DelVotesFor: []DelVote{
+				{Nation: "wilbert", Votes: 120, Timestamp: 1581700100},
+			},
+		},
+		Proposals: []Proposal{
+			{
+				ID:         "maxtopia_1581650000",
+				Name:       "Free Tests For All",
+				Category:   "Health",
+				Option:     "Healthcare",
+				ProposedBy: "maxtopia",
+				Coauthors:  []string{"wilbert"},
+				Approvals:  NameList{"wilbert", "testlandia"},
+				Created:    1581650000,
+			},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %+v, wanted %+v", got, want)
+	}
+	wantTallies := []VoteTally{{10, 3}, {2500, 1000}, {5000, 2000}}
+	if tallies := got.Resolution.Tallies(); !reflect.DeepEqual(tallies, wantTallies) {
+		t.Fatalf("got tallies %v, wanted %v", tallies, wantTallies)
+	}
+}
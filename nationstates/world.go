@@ -0,0 +1,199 @@
+package nationstates
+
+import (
+	"context"
+	"encoding/xml"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// World holds the shards returned by the World API.
+type World struct {
+	XMLName xml.Name `xml:"WORLD"`
+	// Happenings are events from across the world, newest first.
+	Happenings []Event `xml:"HAPPENINGS>EVENT"`
+	// NewNations are the most recently founded nations, newest first.
+	NewNations     CommaList `xml:"NEWNATIONS"`
+	FeaturedRegion string    `xml:"FEATUREDREGION"`
+	// CensusRanks is a page of the rankings on the scale selected with
+	// CensusScales, starting from the rank selected with Start.
+	CensusRanks CensusRanks `xml:"CENSUSRANKS"`
+	Dispatches  []Dispatch  `xml:"DISPATCHLIST>DISPATCH"`
+	Regions     CommaList   `xml:"REGIONS"`
+	// Census holds the world's scores on the scales selected with
+	// CensusScales.
+	Census      []CensusScale `xml:"CENSUS>SCALE"`
+	CensusName  CensusText    `xml:"CENSUSNAME"`
+	CensusTitle CensusText    `xml:"CENSUSTITLE"`
+	CensusUnit  CensusText    `xml:"CENSUSSCALE"`
+	CensusDesc  CensusDesc    `xml:"CENSUSDESC"`
+}
+
+// CensusText is the name, title or unit of the census scale selected with CensusScales.
+type CensusText struct {
+	ID   int    `xml:"id,attr"`
+	Text string `xml:",chardata"`
+}
+
+// CensusDesc describes what the census scale selected with CensusScales
+// measures, for nations and for regions.
+type CensusDesc struct {
+	ID     int    `xml:"id,attr"`
+	Nation string `xml:"NDESC"`
+	Region string `xml:"RDESC"`
+}
+
+// CensusRanks is a page of the world census rankings for a single scale.
+type CensusRanks struct {
+	ID int `xml:"id,attr"`
+	// Nations are in rank order, from the highest score down.
+	Nations []CensusRank `xml:"NATIONS>NATION"`
+}
+
+// CensusRank is a nation's place in the world census rankings.
+type CensusRank struct {
+	Name string `xml:"NAME"`
+	// Rank is the nation's position in the world, where 1 is the highest
+	// score.
+	Rank  int     `xml:"RANK"`
+	Score float64 `xml:"SCORE"`
+}
+
+// Dispatch is a dispatch listing. The text of a dispatch is not included.
+type Dispatch struct {
+	ID          int    `xml:"id,attr"`
+	Title       string `xml:"TITLE"`
+	Author      string `xml:"AUTHOR"`
+	Category    string `xml:"CATEGORY"`
+	Subcategory string `xml:"SUBCATEGORY"`
+	// Created and Edited are when the dispatch was written and last changed,
+	// as Unix timestamps.
+	Created int `xml:"CREATED"`
+	Edited  int `xml:"EDITED"`
+	Views   int `xml:"VIEWS"`
+	// Score is the number of upvotes the dispatch has received.
+	Score int `xml:"SCORE"`
+}
+
+// CommaList is a list of nation or region names which NationStates separates
+// with commas.
+type CommaList []string
+
+func (l *CommaList) UnmarshalText(text []byte) error {
+	s := strings.TrimSpace(string(text))
+	if s == "" {
+		*l = nil
+		return nil
+	}
+	*l = strings.Split(s, ",")
+	return nil
+}
+
+const (
+	HappeningsFilterLaw        = "law"
+	HappeningsFilterChange     = "change"
+	HappeningsFilterDispatch   = "dispatch"
+	HappeningsFilterRMB        = "rmb"
+	HappeningsFilterEmbassy    = "embassy"
+	HappeningsFilterEject      = "eject"
+	HappeningsFilterAdmin      = "admin"
+	HappeningsFilterMove       = "move"
+	HappeningsFilterFounding   = "founding"
+	HappeningsFilterCTE        = "cte"
+	HappeningsFilterVote       = "vote"
+	HappeningsFilterResolution = "resolution"
+	HappeningsFilterMember     = "member"
+	HappeningsFilterEndo       = "endo"
+)
+
+// HappeningsFilter selects which events are returned by the world happenings
+// shard. It is a Param for GetWorld.
+type HappeningsFilter struct {
+	// View restricts events to particular nations or regions. Use
+	// ViewNations or ViewRegions to build it.
+	View string
+	// Filter restricts events to the given HappeningsFilter types.
+	Filter []string
+	Limit  int
+	// SinceID and BeforeID restrict events to those with IDs greater or less than them.
+	SinceID  int
+	BeforeID int
+	// SinceTime and BeforeTime restrict events to those with timestamps greater or less than them.
+	SinceTime  int
+	BeforeTime int
+}
+
+// ViewNations returns a happenings view of events involving the given nations.
+func ViewNations(nations ...string) string {
+	return "nation." + strings.Join(nations, ",")
+}
+
+// ViewRegions returns a happenings view of events in the given regions.
+func ViewRegions(regions ...string) string {
+	return "region." + strings.Join(regions, ",")
+}
+
+func (f HappeningsFilter) setParam(params url.Values) {
+	if f.View != "" {
+		params.Set("view", f.View)
+	}
+	if len(f.Filter) > 0 {
+		params.Set("filter", strings.Join(f.Filter, "+"))
+	}
+	if f.Limit > 0 {
+		params.Set("limit", strconv.Itoa(f.Limit))
+	}
+	if f.SinceID > 0 {
+		params.Set("sinceid", strconv.Itoa(f.SinceID))
+	}
+	if f.BeforeID > 0 {
+		params.Set("beforeid", strconv.Itoa(f.BeforeID))
+	}
+	if f.SinceTime > 0 {
+		params.Set("sincetime", strconv.Itoa(f.SinceTime))
+	}
+	if f.BeforeTime > 0 {
+		params.Set("beforetime", strconv.Itoa(f.BeforeTime))
+	}
+}
+
+// GetWorld is a generic method for querying the World API.
+func (c *Client) GetWorld(shards []Shard, params ...Param) (World, error) {
+	return c.GetWorldContext(context.Background(), shards, params...)
+}
+
+// GetWorldContext is like GetWorld but with a context.
+func (c *Client) GetWorldContext(ctx context.Context, shards []Shard, params ...Param) (World, error) {
+	var w World
+	err := c.doPublic(ctx, query(shards, params), &w)
+	return w, err
+}
+
+// GetHappenings is a convenience method for getting world happenings matching filter.
+func (c *Client) GetHappenings(filter HappeningsFilter) ([]Event, error) {
+	return c.GetHappeningsContext(context.Background(), filter)
+}
+
+// GetHappeningsContext is like GetHappenings but with a context.
+func (c *Client) GetHappeningsContext(ctx context.Context, filter HappeningsFilter) ([]Event, error) {
+	w, err := c.GetWorldContext(ctx, []Shard{ShardHappenings}, filter)
+	if err != nil {
+		return nil, err
+	}
+	return w.Happenings, nil
+}
+
+// GetNewNations is a convenience method for getting the 50 most recently founded nations.
+func (c *Client) GetNewNations() ([]string, error) {
+	return c.GetNewNationsContext(context.Background())
+}
+
+// GetNewNationsContext is like GetNewNations but with a context.
+func (c *Client) GetNewNationsContext(ctx context.Context) ([]string, error) {
+	w, err := c.GetWorldContext(ctx, []Shard{ShardNewNations})
+	if err != nil {
+		return nil, err
+	}
+	return w.NewNations, nil
+}
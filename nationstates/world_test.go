@@ -0,0 +1,85 @@
+package nationstates
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestUnmarshalWorld(t *testing.T) {
+	s := `<WORLD>
+  <NEWNATIONS>testlandia,wilbert,maxtopia</NEWNATIONS>
+  <FEATUREDREGION>testregionia</FEATUREDREGION>
+  <CENSUSRANKS id="46">
+    <NATIONS>
+      <NATION>
+        <NAME>testlandia</NAME>
+        <RANK>1</RANK>
+        <SCORE>21345.67</SCORE>
+      </NATION>
+    </NATIONS>
+  </CENSUSRANKS>
+  <DISPATCHLIST>
+    <DISPATCH id="1310572">
+      <TITLE>Welcome to Testregionia</TITLE>
+      <AUTHOR>testlandia</AUTHOR>
+      <CATEGORY>Meta</CATEGORY>
+      <SUBCATEGORY>Reference</SUBCATEGORY>
+      <CREATED>1581740400</CREATED>
+      <EDITED>1581740500</EDITED>
+      <VIEWS>42</VIEWS>
+      <SCORE>7</SCORE>
+    </DISPATCH>
+  </DISPATCHLIST>
+  <REGIONS>testregionia,lazarus</REGIONS>
+</WORLD>
+`
+	var got World
+	err := xml.Unmarshal([]byte(s), &got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := World{
+		XMLName:        xml.Name{Local: "WORLD"},
+		NewNations:     CommaList{"testlandia", "wilbert", "maxtopia"},
+		FeaturedRegion: "testregionia",
+		CensusRanks: CensusRanks{
+			ID:      CensusDefenseForces,
+			Nations: []CensusRank{{Name: "testlandia", Rank: 1, Score: 21345.67}},
+		},
+		Dispatches: []Dispatch{
+			{ID: 1310572, Title: "Welcome to Testregionia", Author: "testlandia", Category: "Meta", Subcategory: "Reference", Created: 1581740400, Edited: 1581740500, Views: 42, Score: 7},
+		},
+		Regions: CommaList{"testregionia", "lazarus"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %+v, wanted %+v", got, want)
+	}
+}
+
+func TestGetHappenings(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		w.Write([]byte(`<WORLD><HAPPENINGS><EVENT id="2"><TIMESTAMP>1581740500</TIMESTAMP><TEXT>@@testlandia@@ was founded in %%the rejected realms%%.</TEXT></EVENT></HAPPENINGS></WORLD>`))
+	}))
+	defer server.Close()
+	c := NewClient(WithBaseURL(server.URL))
+	events, err := c.GetHappenings(HappeningsFilter{
+		View:    ViewRegions("the rejected realms"),
+		Filter:  []string{HappeningsFilterFounding, HappeningsFilterMove},
+		Limit:   10,
+		SinceID: 1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "filter=founding%2Bmove&limit=10&q=happenings&sinceid=1&view=region.the+rejected+realms"; query != want {
+		t.Errorf("got query %q, wanted %q", query, want)
+	}
+	if len(events) != 1 || events[0].ID != 2 {
+		t.Fatalf("got events %+v, wanted a single event with id 2", events)
+	}
+}
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yi-jiayu/nationstates-secretary/nationstates"
+)
+
+func TestNotifierDeliveryFailure(t *testing.T) {
+	server := newNoticesServer(100, 200, 200, 300)
+	defer server.Close()
+	offsetter := NewInMemoryOffsetter(0)
+	var sent []int
+	failures := 0
+	n := Notifier{
+		PollInterval: time.Hour,
+		Client:       nationstates.NewClient(nationstates.WithBaseURL(server.URL)),
+		Nation:       "testlandia",
+		Offsetter:    offsetter,
+		MaxAttempts:  2,
+		RetryBackoff: time.Millisecond,
+		Callback: func(notice nationstates.Notice, nation nationstates.Nation) error {
+			// the second notice at 200 fails until Telegram comes back
+			if notice.Timestamp == 200 && len(sent) == 2 && failures < 3 {
+				failures++
+				return errors.New("telegram unavailable")
+			}
+			sent = append(sent, notice.Timestamp)
+			return nil
+		},
+	}
+
+	err := n.poll()
+	if err == nil {
+		t.Fatal("got no error from poll after delivery failed")
+	}
+	n.handlePollError(err)
+	if got, want := offsetter.Offset(), 101; got != want {
+		t.Fatalf("got offset %d, wanted %d so that both notices at 200 are retried", got, want)
+	}
+	if failures != 2 {
+		t.Fatalf("got %d attempts, wanted 2", failures)
+	}
+	if n.failures != 1 {
+		t.Fatalf("got %d poll failures, wanted 1", n.failures)
+	}
+	if d := n.backoff(); d >= n.PollInterval {
+		t.Fatalf("got retry after %s, wanted sooner than the poll interval %s", d, n.PollInterval)
+	}
+
+	n.handlePollError(n.poll())
+	if n.failures != 0 {
+		t.Fatalf("got %d poll failures after delivery recovered, wanted 0", n.failures)
+	}
+	if got, want := offsetter.Offset(), 301; got != want {
+		t.Fatalf("got offset %d, wanted %d", got, want)
+	}
+	want := []int{100, 200, 200, 200, 300}
+	if len(sent) != len(want) {
+		t.Fatalf("got notices %v, wanted %v", sent, want)
+	}
+	for i := range want {
+		if sent[i] != want[i] {
+			t.Fatalf("got notices %v, wanted %v", sent, want)
+		}
+	}
+}
+
+func TestClassifyPollError(t *testing.T) {
+	cases := []struct {
+		err  error
+		want pollErrorKind
+	}{
+		{&nationstates.APIError{StatusCode: http.StatusForbidden}, pollErrorAuth},
+		{&nationstates.APIError{StatusCode: http.StatusConflict}, pollErrorAuth},
+		{&nationstates.APIError{StatusCode: http.StatusNotFound}, pollErrorNotFound},
+		{&nationstates.APIError{StatusCode: http.StatusServiceUnavailable}, pollErrorTransient},
+		{&nationstates.APIError{StatusCode: http.StatusTooManyRequests}, pollErrorTransient},
+		{context.DeadlineExceeded, pollErrorTransient},
+	}
+	for _, c := range cases {
+		if got := classifyPollError(c.err); got != c.want {
+			t.Errorf("%v: got %d, wanted %d", c.err, got, c.want)
+		}
+	}
+}
+
+func TestNotifierAlerts(t *testing.T) {
+	var alerts []string
+	n := Notifier{
+		PollInterval:   time.Hour,
+		Nation:         "testlandia",
+		AlertThreshold: 3,
+		Alert: func(text string) {
+			alerts = append(alerts, text)
+		},
+	}
+	transient := &nationstates.APIError{StatusCode: http.StatusBadGateway}
+	n.handlePollError(transient)
+	n.handlePollError(transient)
+	if len(alerts) != 0 {
+		t.Fatalf("got alerts %v before the threshold", alerts)
+	}
+	n.handlePollError(transient)
+	n.handlePollError(transient)
+	if len(alerts) != 1 {
+		t.Fatalf("got %d alerts after the threshold, wanted 1", len(alerts))
+	}
+	n.handlePollError(nil)
+	if len(alerts) != 2 || n.failures != 0 {
+		t.Fatalf("got alerts %v and %d failures after recovering", alerts, n.failures)
+	}
+	n.handlePollError(&nationstates.APIError{StatusCode: http.StatusForbidden})
+	if len(alerts) != 3 {
+		t.Fatalf("got %d alerts, wanted rejected credentials to alert immediately", len(alerts))
+	}
+}
+
+func TestNotifierBackoff(t *testing.T) {
+	n := Notifier{PollInterval: 10 * time.Minute}
+	for failures, want := range map[int]time.Duration{
+		1: time.Minute,
+		2: 2 * time.Minute,
+		4: 8 * time.Minute,
+		5: 10 * time.Minute,
+		9: 10 * time.Minute,
+	} {
+		n.failures = failures
+		if got := n.backoff(); got != want {
+			t.Errorf("%d failures: got backoff %s, wanted %s", failures, got, want)
+		}
+	}
+}
+
+func TestNotifierSkipsRejectedNotice(t *testing.T) {
+	server := newNoticesServer(100, 200)
+	defer server.Close()
+	offsetter := NewInMemoryOffsetter(0)
+	attempts := 0
+	var alerts []string
+	n := Notifier{
+		PollInterval: time.Hour,
+		Client:       nationstates.NewClient(nationstates.WithBaseURL(server.URL)),
+		Nation:       "testlandia",
+		Offsetter:    offsetter,
+		MaxAttempts:  3,
+		RetryBackoff: time.Millisecond,
+		Callback: func(notice nationstates.Notice, nation nationstates.Nation) error {
+			if notice.Timestamp == 100 {
+				attempts++
+				return &TelegramAPIError{Code: http.StatusBadRequest, Description: "Bad Request: can't parse entities"}
+			}
+			return nil
+		},
+		Alert: func(text string) {
+			alerts = append(alerts, text)
+		},
+	}
+	n.poll()
+	if attempts != 1 {
+		t.Fatalf("got %d attempts, wanted a rejected notice to be attempted once", attempts)
+	}
+	if got, want := offsetter.Offset(), 201; got != want {
+		t.Fatalf("got offset %d, wanted %d", got, want)
+	}
+	if len(alerts) != 1 {
+		t.Fatalf("got alerts %v, wanted 1 for the skipped notice", alerts)
+	}
+}
+
+func TestIssueSenderResumes(t *testing.T) {
+	var texts []string
+	fail := true
+	telegram := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req SendMessageRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Error(err)
+			return
+		}
+		// the second option fails once
+		if strings.HasPrefix(req.Text, "Option 2") && fail {
+			fail = false
+			w.Write([]byte(`{"ok":false,"error_code":502,"description":"Bad Gateway"}`))
+			return
+		}
+		texts = append(texts, req.Text)
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer telegram.Close()
+	defer func(u string) { telegramBaseURL = u }(telegramBaseURL)
+	telegramBaseURL = telegram.URL
+
+	s := newIssueSender("token", 1)
+	notice := nationstates.Notice{Type: nationstates.NoticeIssue, URL: "page=show_dilemma/dilemma=7"}
+	issues := []nationstates.Issue{{
+		ID:      7,
+		Title:   "Fish & Chips",
+		Text:    "<b>Should</b> we?",
+		Options: []nationstates.Option{{ID: 0, Text: "Option 1"}, {ID: 1, Text: "Option 2"}},
+	}}
+	if err := s.send(notice, issues); err == nil {
+		t.Fatal("got no error when an option could not be sent")
+	}
+	if err := s.send(notice, issues); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"<strong>New Issue: Fish &amp; Chips</strong>\n&lt;b&gt;Should&lt;/b&gt; we?", "Option 1", "Option 2"}
+	if len(texts) != len(want) {
+		t.Fatalf("got messages %q, wanted %q", texts, want)
+	}
+	for i := range want {
+		if texts[i] != want[i] {
+			t.Fatalf("got messages %q, wanted %q", texts, want)
+		}
+	}
+}
+
+func TestFormatConsequences(t *testing.T) {
+	conseq := nationstates.Consequences{
+		Desc:              "citizens <b>cheer</b> as taxes & tariffs fall",
+		Reclassifications: []nationstates.Reclassification{{Type: nationstates.ReclassifyEconomy, From: "Good", To: "Strong"}},
+		NewPolicies:       []nationstates.Policy{{Name: "No Income Tax", Desc: "Income tax is <i>abolished</i>."}},
+		RemovedPolicies:   []nationstates.Policy{{Name: "Tariffs & Duties"}},
+		Unlocks:           []string{"r1"},
+		Headlines:         []string{"Finance minister says \"<3\""},
+		Rankings: []nationstates.Rank{
+			{ID: nationstates.CensusEconomy, Score: 60, PChange: 1.5},
+			{ID: nationstates.CensusCrime, Score: 1200, PChange: 4},
+		},
+	}
+	want := "<strong>The Talking Point</strong>\nCitizens &lt;b&gt;cheer&lt;/b&gt; as taxes &amp; tariffs fall.\n\n" +
+		"<strong>Reclassifications</strong>\nEconomy: Good → Strong\n\n" +
+		"<strong>Policies</strong>\n✅ No Income Tax: Income tax is &lt;i&gt;abolished&lt;/i&gt;.\n❌ Tariffs &amp; Duties\n\n" +
+		"<strong>Unlocked</strong>\n1 new banner(s): <a href=\"" + nationstates.BannerURL("r1") + "\">r1</a>\n\n" +
+		"<strong>Recent Headlines</strong>\nFinance minister says &#34;&lt;3&#34;\n\n" +
+		"<strong>Recent trends</strong>\n📈 Crime: 4.00% (1200.00 Crimes per hour) 👎\n📈 Economy: 1.50% (60.00 Points) 👍"
+	if got := formatConsequences(conseq); got != want {
+		t.Fatalf("got\n%s\nwanted\n%s", got, want)
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"os"
+	"sync"
+
+	"github.com/yi-jiayu/nationstates-secretary/internal/atomicfile"
+)
+
+// FileOffsetter is an Offsetter which saves the offset to a file, so that
+// notices are not sent again after a restart. The file is replaced
+// atomically and synced to disk on every update.
+type FileOffsetter struct {
+	Path string
+
+	mu     sync.Mutex
+	offset int
+}
+
+type offsetFile struct {
+	Offset int `json:"offset"`
+}
+
+// NewFileOffsetter returns a FileOffsetter which saves the offset to path,
+// starting from offset if nothing has been saved yet. If the file is corrupt,
+// the offset from an interrupted update is used if it is intact, and offset
+// otherwise.
+func NewFileOffsetter(path string, offset int) (*FileOffsetter, error) {
+	o := &FileOffsetter{Path: path, offset: offset}
+	found := false
+	// a crash after the temporary file is synced but before it is renamed
+	// leaves the newest offset in the temporary file
+	for _, p := range []string{path, path + ".tmp"} {
+		data, err := ioutil.ReadFile(p)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		var saved offsetFile
+		err = json.Unmarshal(data, &saved)
+		if err != nil {
+			log.Printf("ignoring corrupt offset file %s: %v", p, err)
+			continue
+		}
+		if !found || saved.Offset > o.offset {
+			o.offset = saved.Offset
+		}
+		found = true
+	}
+	return o, nil
+}
+
+func (o *FileOffsetter) Offset() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.offset
+}
+
+// SetOffset updates the offset and saves it. If it cannot be saved, the error
+// is logged and the offset is only kept in memory.
+func (o *FileOffsetter) SetOffset(offset int) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.offset = offset
+	err := o.saveLocked()
+	if err != nil {
+		log.Printf("saving offset: %v", err)
+	}
+}
+
+// saveLocked saves the offset with atomicfile.WriteFile, so that Path always
+// holds either the old or the new offset.
+func (o *FileOffsetter) saveLocked() error {
+	data, err := json.Marshal(offsetFile{Offset: o.offset})
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(o.Path, data)
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/yi-jiayu/nationstates-secretary/nationstates"
+)
+
+func TestFileOffsetterPersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "offset.json")
+	o, err := NewFileOffsetter(path, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := o.Offset(); got != 5 {
+		t.Fatalf("got offset %d for new file, wanted 5", got)
+	}
+	o.SetOffset(42)
+	o, err = NewFileOffsetter(path, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := o.Offset(); got != 42 {
+		t.Fatalf("got offset %d after restart, wanted 42", got)
+	}
+}
+
+func TestFileOffsetterCrashBeforeRename(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "offset.json")
+	ioutil.WriteFile(path, []byte(`{"offset":10}`), 0600)
+	ioutil.WriteFile(path+".tmp", []byte(`{"offset":20}`), 0600)
+	o, err := NewFileOffsetter(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := o.Offset(); got != 20 {
+		t.Fatalf("got offset %d, wanted the synced but unrenamed offset 20", got)
+	}
+}
+
+func TestFileOffsetterCorrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "offset.json")
+	ioutil.WriteFile(path, []byte("\x00\x00\x00"), 0600)
+	// a crash while writing the temporary file leaves it truncated
+	ioutil.WriteFile(path+".tmp", []byte(`{"off`), 0600)
+	o, err := NewFileOffsetter(path, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := o.Offset(); got != 7 {
+		t.Fatalf("got offset %d, wanted initial offset 7", got)
+	}
+	o.SetOffset(8)
+	o, err = NewFileOffsetter(path, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := o.Offset(); got != 8 {
+		t.Fatalf("got offset %d after recovering, wanted 8", got)
+	}
+}
+
+// newNoticesServer returns a server with one notice at each of timestamps
+// which honours the from parameter.
+func newNoticesServer(timestamps ...int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		from, _ := strconv.Atoi(r.URL.Query().Get("from"))
+		fmt.Fprint(w, `<NATION id="testlandia"><NOTICES>`)
+		// notices are returned newest first
+		for i := len(timestamps) - 1; i >= 0; i-- {
+			if timestamps[i] >= from {
+				fmt.Fprintf(w, `<NOTICE><TIMESTAMP>%d</TIMESTAMP><TYPE>I</TYPE></NOTICE>`, timestamps[i])
+			}
+		}
+		fmt.Fprint(w, `</NOTICES></NATION>`)
+	}))
+}
+
+func TestNotifierRestart(t *testing.T) {
+	server := newNoticesServer(100, 200)
+	defer server.Close()
+	client := nationstates.NewClient(nationstates.WithBaseURL(server.URL))
+	path := filepath.Join(t.TempDir(), "offset.json")
+	var sent []int
+	newNotifier := func() Notifier {
+		o, err := NewFileOffsetter(path, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return Notifier{
+			PollInterval: time.Hour,
+			Client:       client,
+			Nation:       "testlandia",
+			Offsetter:    o,
+			Callback: func(notice nationstates.Notice, nation nationstates.Nation) error {
+				sent = append(sent, notice.Timestamp)
+				return nil
+			},
+		}
+	}
+
+	newNotifier().poll()
+	if len(sent) != 2 {
+		t.Fatalf("got %d notices sent, wanted 2", len(sent))
+	}
+	// the process restarts after the offset was saved
+	newNotifier().poll()
+	if len(sent) != 2 {
+		t.Fatalf("got notices %v sent again after restart", sent[2:])
+	}
+
+	// the process crashes between polling and saving the offset, so the
+	// notices are sent again rather than lost
+	ioutil.WriteFile(path, []byte(`{"offset":150}`), 0600)
+	newNotifier().poll()
+	if len(sent) != 3 || sent[2] != 200 {
+		t.Fatalf("got notices %v, wanted 200 to be sent again", sent)
+	}
+}
@@ -0,0 +1,374 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io/ioutil"
+	"log"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yi-jiayu/nationstates-secretary/internal/atomicfile"
+	"github.com/yi-jiayu/nationstates-secretary/nationstates"
+)
+
+// defaultPuppetPatterns match the names of nations which are probably
+// puppets, such as those ending in a number or a roman numeral.
+var defaultPuppetPatterns = []string{
+	`[0-9]+$`,
+	`(?i)\s(ii|iii|iv|v|vi|vii|viii|ix|x)$`,
+}
+
+// foundingPattern matches the text of founding happenings, for example
+// "@@testlandia@@ was founded in %%the_pacific%%."
+var foundingPattern = regexp.MustCompile(`@@([^@]+)@@ was (?:re)?founded in %%([^%]+)%%`)
+
+// recruitmentRetention is how long recruited nations are remembered for, so
+// that a nation is not telegrammed again after a restart.
+const recruitmentRetention = 30 * 24 * time.Hour
+
+// Campaign is a recruitment campaign which telegrams newly founded nations.
+type Campaign struct {
+	Name     string                `json:"name"`
+	Telegram nationstates.Telegram `json:"telegram"`
+	// FoundedIn restricts the campaign to nations founded in these regions,
+	// such as the feeders. All regions are accepted if it is empty.
+	FoundedIn []string `json:"founded_in"`
+	// ExcludeRegions are regions whose nations are not recruited, typically
+	// our own region and its allies. The region is checked again before the
+	// telegram is sent, in case the nation has moved since it was founded.
+	ExcludeRegions []string `json:"exclude_regions"`
+	// ExcludePatterns are regular expressions for the names of nations which
+	// are not recruited. defaultPuppetPatterns are used if it is empty.
+	ExcludePatterns []string `json:"exclude_patterns"`
+
+	patterns []*regexp.Regexp
+}
+
+// accepts reports whether nation, founded in region, should be recruited by c.
+func (c Campaign) accepts(nation, region string) bool {
+	if len(c.FoundedIn) > 0 && !containsName(c.FoundedIn, region) {
+		return false
+	}
+	if containsName(c.ExcludeRegions, region) {
+		return false
+	}
+	name := displayName(nation)
+	for _, pattern := range c.patterns {
+		if pattern.MatchString(name) {
+			return false
+		}
+	}
+	return true
+}
+
+// CampaignStats counts what happened to the candidates for a campaign.
+type CampaignStats struct {
+	Queued int `json:"queued"`
+	Sent   int `json:"sent"`
+	Failed int `json:"failed"`
+	// Dropped counts candidates which were not queued because the queue was full.
+	Dropped int `json:"dropped"`
+	// QueueFailed counts candidates which could not be queued because the
+	// queue could not be saved.
+	QueueFailed int `json:"queue_failed"`
+	// Excluded counts queued telegrams which were not sent because the
+	// nation had moved into an excluded region or ceased to exist.
+	Excluded int `json:"excluded"`
+}
+
+type recruitmentState struct {
+	SinceID int `json:"since_id"`
+	// Seen maps the nations which have been considered to the time they were
+	// founded.
+	Seen  map[string]int64          `json:"seen"`
+	Stats map[string]*CampaignStats `json:"stats"`
+}
+
+// Recruiter watches for newly founded nations and queues recruitment
+// telegrams for them. Each nation is recruited by the first campaign which
+// accepts it.
+type Recruiter struct {
+	PollInterval   time.Duration
+	ReportInterval time.Duration
+	Client         *nationstates.Client
+	Queue          *nationstates.TelegramQueue
+	Campaigns      []Campaign
+	// MaxPending is the number of telegrams the queue may hold before new
+	// candidates are dropped, since nations are founded much faster than
+	// recruitment telegrams can be sent and stale candidates are worthless.
+	MaxPending int
+	StateFile  string
+	// Report is called with the campaign statistics every ReportInterval.
+	Report func(text string)
+
+	mu    sync.Mutex
+	state recruitmentState
+}
+
+// NewRecruiter returns a Recruiter for campaigns, restoring its state from
+// stateFile.
+func NewRecruiter(client *nationstates.Client, queue *nationstates.TelegramQueue, campaigns []Campaign, stateFile string) (*Recruiter, error) {
+	for i := range campaigns {
+		patterns := campaigns[i].ExcludePatterns
+		if len(patterns) == 0 {
+			patterns = defaultPuppetPatterns
+		}
+		for _, pattern := range patterns {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("campaign %s: %w", campaigns[i].Name, err)
+			}
+			campaigns[i].patterns = append(campaigns[i].patterns, re)
+		}
+	}
+	r := &Recruiter{
+		PollInterval:   5 * time.Minute,
+		ReportInterval: 24 * time.Hour,
+		Client:         client,
+		Queue:          queue,
+		Campaigns:      campaigns,
+		MaxPending:     20,
+		StateFile:      stateFile,
+	}
+	data, err := ioutil.ReadFile(stateFile)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err == nil {
+		err = json.Unmarshal(data, &r.state)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if r.state.Seen == nil {
+		r.state.Seen = make(map[string]int64)
+	}
+	if r.state.Stats == nil {
+		r.state.Stats = make(map[string]*CampaignStats)
+	}
+	return r, nil
+}
+
+func (r *Recruiter) stats(campaign string) *CampaignStats {
+	s := r.state.Stats[campaign]
+	if s == nil {
+		s = new(CampaignStats)
+		r.state.Stats[campaign] = s
+	}
+	return s
+}
+
+func (r *Recruiter) poll() {
+	r.mu.Lock()
+	sinceID := r.state.SinceID
+	r.mu.Unlock()
+	ctx, cancel := context.WithTimeout(context.Background(), apiTimeout)
+	events, err := r.Client.GetHappeningsContext(ctx, nationstates.HappeningsFilter{
+		Filter:  []string{nationstates.HappeningsFilterFounding},
+		SinceID: sinceID,
+	})
+	cancel()
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	// happenings are returned newest first, but the oldest candidates should
+	// be queued first
+	for i := len(events) - 1; i >= 0; i-- {
+		r.consider(events[i])
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cutoff := time.Now().Add(-recruitmentRetention).Unix()
+	for nation, founded := range r.state.Seen {
+		if founded < cutoff {
+			delete(r.state.Seen, nation)
+		}
+	}
+	err = r.saveLocked()
+	if err != nil {
+		log.Println(err)
+	}
+}
+
+// consider queues a recruitment telegram for the nation founded in event.
+func (r *Recruiter) consider(event nationstates.Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if event.ID > r.state.SinceID {
+		r.state.SinceID = event.ID
+	}
+	m := foundingPattern.FindStringSubmatch(event.Text)
+	if m == nil {
+		return
+	}
+	nation, region := m[1], m[2]
+	if _, ok := r.state.Seen[nation]; ok {
+		return
+	}
+	r.state.Seen[nation] = int64(event.Timestamp)
+	for _, campaign := range r.Campaigns {
+		if !campaign.accepts(nation, region) {
+			continue
+		}
+		stats := r.stats(campaign.Name)
+		if r.MaxPending > 0 && r.Queue.Len() >= r.MaxPending {
+			stats.Dropped++
+			return
+		}
+		err := r.Queue.EnqueueCampaign(campaign.Name, campaign.Telegram, nation)
+		if err != nil {
+			log.Println(err)
+			stats.QueueFailed++
+			return
+		}
+		stats.Queued++
+		return
+	}
+}
+
+// campaignFor returns the campaign a queued telegram was queued for.
+func (r *Recruiter) campaignFor(t nationstates.QueuedTelegram) (Campaign, bool) {
+	if t.Campaign == "" {
+		return Campaign{}, false
+	}
+	for _, campaign := range r.Campaigns {
+		if campaign.Name == t.Campaign {
+			return campaign, true
+		}
+	}
+	return Campaign{}, false
+}
+
+// SkipTelegram reports whether a queued telegram should not be sent because
+// the nation has moved into one of the campaign's excluded regions or has
+// ceased to exist. It should be used as the queue's Skip hook.
+func (r *Recruiter) SkipTelegram(ctx context.Context, t nationstates.QueuedTelegram) (bool, error) {
+	campaign, ok := r.campaignFor(t)
+	if !ok || len(campaign.ExcludeRegions) == 0 {
+		return false, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, apiTimeout)
+	defer cancel()
+	// the lookup is made without our credentials, so that a nation which has
+	// ceased to exist does not end our login session
+	n, err := r.Client.GetPublicNationContext(ctx, t.To, []nationstates.Shard{nationstates.ShardRegion})
+	switch {
+	case errors.Is(err, nationstates.ErrNotFound):
+	case err != nil:
+		log.Printf("checking the region of %s: %v", t.To, err)
+		return false, err
+	case !containsName(campaign.ExcludeRegions, n.Region):
+		return false, nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stats(campaign.Name).Excluded++
+	err = r.saveLocked()
+	if err != nil {
+		log.Println(err)
+	}
+	return true, nil
+}
+
+// TelegramSent records the outcome of sending a queued telegram. It should
+// be called from the queue's OnSent and OnError hooks.
+func (r *Recruiter) TelegramSent(t nationstates.QueuedTelegram, err error) {
+	campaign, ok := r.campaignFor(t)
+	// telegrams which failed because of transient errors stay in the queue
+	// and will be retried
+	if !ok || err != nil && !nationstates.TelegramRejected(err) {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err != nil {
+		r.stats(campaign.Name).Failed++
+	} else {
+		r.stats(campaign.Name).Sent++
+	}
+	err = r.saveLocked()
+	if err != nil {
+		log.Println(err)
+	}
+}
+
+func (r *Recruiter) report() {
+	r.mu.Lock()
+	text := formatCampaignStats(r.state.Stats, r.Queue.Len())
+	r.mu.Unlock()
+	if r.Report != nil {
+		r.Report(text)
+	}
+}
+
+func (r *Recruiter) saveLocked() error {
+	data, err := json.Marshal(r.state)
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(r.StateFile, data)
+}
+
+func (r *Recruiter) Start() {
+	reports := time.NewTicker(r.ReportInterval)
+	defer reports.Stop()
+	polls := time.NewTicker(r.PollInterval)
+	defer polls.Stop()
+	r.poll()
+	for {
+		select {
+		case <-polls.C:
+			r.poll()
+		case <-reports.C:
+			r.report()
+		}
+	}
+}
+
+func formatCampaignStats(stats map[string]*CampaignStats, pending int) string {
+	names := make([]string, 0, len(stats))
+	for name := range stats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var b strings.Builder
+	b.WriteString("<strong>Recruitment</strong>")
+	for _, name := range names {
+		s := stats[name]
+		fmt.Fprintf(&b, "\n%s: %d sent, %d failed, %d queued, %d dropped", html.EscapeString(name), s.Sent, s.Failed, s.Queued, s.Dropped)
+		if s.QueueFailed > 0 {
+			fmt.Fprintf(&b, ", %d could not be queued", s.QueueFailed)
+		}
+		if s.Excluded > 0 {
+			fmt.Fprintf(&b, ", %d excluded", s.Excluded)
+		}
+	}
+	fmt.Fprintf(&b, "\n%d telegrams pending", pending)
+	return b.String()
+}
+
+// displayName converts a nation ID such as "new_testlandia" to a name which
+// can be matched against patterns.
+func displayName(id string) string {
+	return strings.ReplaceAll(id, "_", " ")
+}
+
+// containsName reports whether names contains name, ignoring case and the
+// difference between spaces and underscores.
+func containsName(names []string, name string) bool {
+	for _, n := range names {
+		if strings.EqualFold(displayName(n), displayName(name)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/yi-jiayu/nationstates-secretary/nationstates"
+)
+
+func newTestRecruiter(t *testing.T, stateFile string, campaigns ...Campaign) *Recruiter {
+	t.Helper()
+	queue, err := nationstates.NewTelegramQueue(nil, "client", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := NewRecruiter(nil, queue, campaigns, stateFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func foundingEvent(id int, nation, region string) nationstates.Event {
+	return nationstates.Event{ID: id, Timestamp: 1600000000 + id, Text: "@@" + nation + "@@ was founded in %%" + region + "%%."}
+}
+
+func TestFoundingPattern(t *testing.T) {
+	cases := []struct {
+		text, nation, region string
+	}{
+		{"@@testlandia@@ was founded in %%the_pacific%%.", "testlandia", "the_pacific"},
+		{"@@new_testlandia@@ was refounded in %%the_north_pacific%%.", "new_testlandia", "the_north_pacific"},
+	}
+	for _, c := range cases {
+		m := foundingPattern.FindStringSubmatch(c.text)
+		if m == nil || m[1] != c.nation || m[2] != c.region {
+			t.Errorf("got %q for %q, wanted nation %q and region %q", m, c.text, c.nation, c.region)
+		}
+	}
+	if m := foundingPattern.FindStringSubmatch("@@testlandia@@ relocated from %%a%% to %%b%%."); m != nil {
+		t.Errorf("got %q for a relocation, wanted no match", m)
+	}
+}
+
+func TestCampaignAccepts(t *testing.T) {
+	r := newTestRecruiter(t, filepath.Join(t.TempDir(), "recruitment.json"), Campaign{
+		Name:           "feeders",
+		FoundedIn:      []string{"The Pacific"},
+		ExcludeRegions: []string{"the_pacific_allies"},
+	}, Campaign{
+		Name:           "everywhere",
+		ExcludeRegions: []string{"Testregionia"},
+	})
+	feeders, everywhere := r.Campaigns[0], r.Campaigns[1]
+	cases := []struct {
+		campaign       Campaign
+		nation, region string
+		want           bool
+	}{
+		{feeders, "testlandia", "the_pacific", true},
+		{feeders, "testlandia", "the_north_pacific", false},
+		{everywhere, "testlandia", "the_north_pacific", true},
+		{everywhere, "testlandia", "testregionia", false},
+		{everywhere, "testlandia_2", "the_pacific", false},
+		{everywhere, "foo_iv", "the_pacific", false},
+		{everywhere, "ivy", "the_pacific", true},
+	}
+	for _, c := range cases {
+		if got := c.campaign.accepts(c.nation, c.region); got != c.want {
+			t.Errorf("got %t for %s in %s with campaign %s, wanted %t", got, c.nation, c.region, c.campaign.Name, c.want)
+		}
+	}
+}
+
+func TestRecruiterSeenAfterRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "recruitment.json")
+	campaign := Campaign{Name: "all", Telegram: nationstates.Telegram{ID: "1", Recruitment: true}}
+	r := newTestRecruiter(t, path, campaign)
+	r.consider(foundingEvent(1, "testlandia", "the_pacific"))
+	r.consider(foundingEvent(2, "testlandia", "the_pacific"))
+	if got := r.Queue.Len(); got != 1 {
+		t.Fatalf("got %d queued telegrams, wanted 1", got)
+	}
+	if err := r.saveLocked(); err != nil {
+		t.Fatal(err)
+	}
+
+	r = newTestRecruiter(t, path, campaign)
+	r.consider(foundingEvent(3, "testlandia", "the_pacific"))
+	r.consider(foundingEvent(4, "otherland", "the_pacific"))
+	if got := r.Queue.Len(); got != 1 {
+		t.Fatalf("got %d queued telegrams after restart, wanted 1", got)
+	}
+	if got := r.state.SinceID; got != 4 {
+		t.Fatalf("got since id %d, wanted 4", got)
+	}
+	if got := r.state.Stats["all"].Queued; got != 2 {
+		t.Fatalf("got %d queued in stats, wanted 2", got)
+	}
+}
+
+func TestRecruiterMaxPending(t *testing.T) {
+	r := newTestRecruiter(t, filepath.Join(t.TempDir(), "recruitment.json"), Campaign{Name: "all", Telegram: nationstates.Telegram{ID: "1"}})
+	r.MaxPending = 1
+	r.consider(foundingEvent(1, "testlandia", "the_pacific"))
+	r.consider(foundingEvent(2, "otherland", "the_pacific"))
+	if got := r.Queue.Len(); got != 1 {
+		t.Fatalf("got %d queued telegrams, wanted 1", got)
+	}
+	if s := r.state.Stats["all"]; s.Queued != 1 || s.Dropped != 1 {
+		t.Fatalf("got stats %+v, wanted 1 queued and 1 dropped", s)
+	}
+}
+
+func TestRecruiterQueueFailed(t *testing.T) {
+	queue, err := nationstates.NewTelegramQueue(nil, "client", filepath.Join(t.TempDir(), "missing", "queue.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := NewRecruiter(nil, queue, []Campaign{{Name: "all", Telegram: nationstates.Telegram{ID: "1"}}}, filepath.Join(t.TempDir(), "recruitment.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.consider(foundingEvent(1, "testlandia", "the_pacific"))
+	if s := r.state.Stats["all"]; s.Queued != 0 || s.QueueFailed != 1 {
+		t.Fatalf("got stats %+v, wanted 0 queued and 1 queue failure", s)
+	}
+}
+
+func TestRecruiterTelegramSent(t *testing.T) {
+	r := newTestRecruiter(t, filepath.Join(t.TempDir(), "recruitment.json"),
+		Campaign{Name: "all", Telegram: nationstates.Telegram{ID: "1"}},
+		Campaign{Name: "same template", Telegram: nationstates.Telegram{ID: "1"}})
+	sent := nationstates.QueuedTelegram{Telegram: nationstates.Telegram{ID: "1"}, To: "testlandia", Campaign: "all"}
+	r.TelegramSent(sent, &nationstates.APIError{StatusCode: 429})
+	r.TelegramSent(sent, errors.New("connection reset"))
+	if s := r.state.Stats["all"]; s != nil {
+		t.Fatalf("got stats %+v after transient errors, wanted none", s)
+	}
+	r.TelegramSent(sent, &nationstates.TelegramError{To: "testlandia", Message: "Client not registered for API."})
+	r.TelegramSent(sent, nil)
+	r.TelegramSent(nationstates.QueuedTelegram{Telegram: nationstates.Telegram{ID: "2"}}, nil)
+	if s := r.state.Stats["all"]; s.Sent != 1 || s.Failed != 1 {
+		t.Fatalf("got stats %+v, wanted 1 sent and 1 failed", s)
+	}
+	if s := r.state.Stats["same template"]; s != nil {
+		t.Fatalf("got stats %+v for a campaign sharing the template, wanted none", s)
+	}
+}
+
+func TestRecruiterSkipTelegram(t *testing.T) {
+	regions := map[string]string{"stayed": "the_pacific", "moved": "Testregionia"}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Password") != "" || r.Header.Get("X-Autologin") != "" || r.Header.Get("X-Pin") != "" {
+			t.Errorf("got credentials in headers %v, wanted none", r.Header)
+		}
+		nation := r.URL.Query().Get("nation")
+		region, ok := regions[nation]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprintf(w, `<NATION id="%s"><REGION>%s</REGION></NATION>`, nation, region)
+	}))
+	defer server.Close()
+	queue, err := nationstates.NewTelegramQueue(nil, "client", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	creds := nationstates.Credentials{Autologin: "autologin", Pin: "12345"}
+	client := nationstates.NewClient(nationstates.WithBaseURL(server.URL), nationstates.WithPassword("hunter2"), nationstates.WithCredentials(creds))
+	r, err := NewRecruiter(client, queue, []Campaign{{Name: "all", ExcludeRegions: []string{"testregionia"}}}, filepath.Join(t.TempDir(), "recruitment.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := map[string]bool{"stayed": false, "moved": true, "ceased": true}
+	for nation, want := range cases {
+		skip, err := r.SkipTelegram(context.Background(), nationstates.QueuedTelegram{To: nation, Campaign: "all"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if skip != want {
+			t.Errorf("got skip %t for %s, wanted %t", skip, nation, want)
+		}
+	}
+	if s := r.state.Stats["all"]; s == nil || s.Excluded != 2 {
+		t.Fatalf("got stats %+v, wanted 2 excluded", s)
+	}
+	if got := client.Credentials(); got != creds {
+		t.Fatalf("got credentials %+v after checking regions, wanted them kept as %+v", got, creds)
+	}
+}
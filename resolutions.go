@@ -0,0 +1,206 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io/ioutil"
+	"log"
+	"os"
+	"time"
+
+	"github.com/yi-jiayu/nationstates-secretary/internal/atomicfile"
+	"github.com/yi-jiayu/nationstates-secretary/nationstates"
+)
+
+var councilNames = map[int]string{
+	nationstates.GeneralAssembly: "General Assembly",
+	nationstates.SecurityCouncil: "Security Council",
+}
+
+var councilPages = map[int]string{
+	nationstates.GeneralAssembly: "https://www.nationstates.net/page=ga",
+	nationstates.SecurityCouncil: "https://www.nationstates.net/page=sc",
+}
+
+// ResolutionWatcher polls the World Assembly councils and calls Callback
+// whenever a new resolution comes to vote.
+type ResolutionWatcher struct {
+	PollInterval time.Duration
+	Client       *nationstates.Client
+	Councils     []int
+	// Callback announces a resolution. If it returns an error, the resolution
+	// is not recorded as announced and is tried again on the next poll.
+	Callback func(council int, resolution nationstates.Resolution) error
+	// StateFile is where the resolutions which have been announced are saved,
+	// so that they are not announced again after a restart. They are only
+	// kept in memory if it is empty.
+	StateFile string
+
+	// seen holds the ID of the last resolution at vote in each council.
+	seen   map[int]string
+	ticker *time.Ticker
+}
+
+// load restores the resolutions which have been announced from StateFile.
+func (w *ResolutionWatcher) load() error {
+	w.seen = make(map[int]string)
+	if w.StateFile == "" {
+		return nil
+	}
+	data, err := ioutil.ReadFile(w.StateFile)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, &w.seen)
+}
+
+func (w *ResolutionWatcher) save() error {
+	if w.StateFile == "" {
+		return nil
+	}
+	data, err := json.Marshal(w.seen)
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(w.StateFile, data)
+}
+
+func (w *ResolutionWatcher) poll() {
+	if w.seen == nil {
+		err := w.load()
+		if err != nil {
+			log.Println(err)
+		}
+	}
+	changed := false
+	for _, council := range w.Councils {
+		ctx, cancel := context.WithTimeout(context.Background(), apiTimeout)
+		resolution, err := w.Client.GetResolutionContext(ctx, council)
+		cancel()
+		if err != nil {
+			log.Println(err)
+			continue
+		}
+		if resolution == nil {
+			if _, ok := w.seen[council]; ok {
+				delete(w.seen, council)
+				changed = true
+			}
+			continue
+		}
+		id := resolution.ID
+		if id == "" {
+			id = resolution.Name
+		}
+		if w.seen[council] == id {
+			continue
+		}
+		err = w.Callback(council, *resolution)
+		if err != nil {
+			log.Printf("announcing resolution %q: %v", resolution.Name, err)
+			continue
+		}
+		w.seen[council] = id
+		changed = true
+	}
+	if changed {
+		err := w.save()
+		if err != nil {
+			log.Println(err)
+		}
+	}
+}
+
+func (w *ResolutionWatcher) Start() {
+	if w.ticker != nil {
+		w.ticker.Stop()
+	}
+	w.poll()
+	w.ticker = time.NewTicker(w.PollInterval)
+	for range w.ticker.C {
+		w.poll()
+	}
+}
+
+func formatTally(council int, resolution nationstates.Resolution) string {
+	return fmt.Sprintf(`<strong>%s: %s</strong>
+For: %d (%d nations)
+Against: %d (%d nations)`,
+		councilNames[council],
+		html.EscapeString(resolution.Name),
+		resolution.TotalVotesFor,
+		resolution.TotalNationsFor,
+		resolution.TotalVotesAgainst,
+		resolution.TotalNationsAgainst)
+}
+
+func tallyButtons(council int) ([][]InlineKeyboardButton, error) {
+	data, err := json.Marshal(CallbackData{
+		Action:  "waTally",
+		Council: council,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return [][]InlineKeyboardButton{
+		{
+			InlineKeyboardButton{
+				Text: "View on NationStates",
+				URL:  councilPages[council],
+			},
+			InlineKeyboardButton{
+				Text:         "Refresh tally",
+				CallbackData: string(data),
+			},
+		},
+	}, nil
+}
+
+func sendResolution(token string, chatID int, council int, resolution nationstates.Resolution) error {
+	text := fmt.Sprintf(`<strong>New at vote in the %s: %s</strong>
+Category: %s (%s)
+Proposed by: %s
+
+For: %d (%d nations)
+Against: %d (%d nations)`,
+		councilNames[council],
+		html.EscapeString(resolution.Name),
+		html.EscapeString(resolution.Category),
+		html.EscapeString(resolution.Option),
+		html.EscapeString(resolution.ProposedBy),
+		resolution.TotalVotesFor,
+		resolution.TotalNationsFor,
+		resolution.TotalVotesAgainst,
+		resolution.TotalNationsAgainst)
+	buttons, err := tallyButtons(council)
+	if err != nil {
+		return err
+	}
+	return sendMessageWithInlineKeyboard(token, chatID, text, buttons)
+}
+
+func sendTally(ctx context.Context, client *nationstates.Client, token string, chatID int, council int) error {
+	resolution, err := client.GetResolutionContext(ctx, council)
+	if err != nil {
+		return err
+	}
+	if resolution == nil {
+		return sendMessage(token, chatID, fmt.Sprintf("There is no resolution at vote in the %s.", councilNames[council]))
+	}
+	buttons, err := tallyButtons(council)
+	if err != nil {
+		return err
+	}
+	return sendMessageWithInlineKeyboard(token, chatID, formatTally(council, *resolution), buttons)
+}
+
+func newResolutionCallback(token string, chatID int) func(council int, resolution nationstates.Resolution) error {
+	return func(council int, resolution nationstates.Resolution) error {
+		return sendResolution(token, chatID, council, resolution)
+	}
+}
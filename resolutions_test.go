@@ -0,0 +1,67 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/yi-jiayu/nationstates-secretary/nationstates"
+)
+
+func TestResolutionWatcherRestart(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<WA council="1"><RESOLUTION><ID>ga_42</ID><NAME>Test Resolution</NAME></RESOLUTION></WA>`))
+	}))
+	defer server.Close()
+	client := nationstates.NewClient(nationstates.WithBaseURL(server.URL))
+	path := filepath.Join(t.TempDir(), "resolutions.json")
+	var announced []string
+	newWatcher := func() *ResolutionWatcher {
+		return &ResolutionWatcher{
+			PollInterval: time.Hour,
+			Client:       client,
+			Councils:     []int{nationstates.GeneralAssembly},
+			StateFile:    path,
+			Callback: func(council int, resolution nationstates.Resolution) error {
+				announced = append(announced, resolution.Name)
+				return nil
+			},
+		}
+	}
+	newWatcher().poll()
+	newWatcher().poll()
+	if len(announced) != 1 {
+		t.Fatalf("got resolutions %v announced, wanted the resolution at vote announced once across restarts", announced)
+	}
+}
+
+func TestResolutionWatcherRetriesFailedAnnouncement(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<WA council="1"><RESOLUTION><ID>ga_42</ID><NAME>Test Resolution</NAME></RESOLUTION></WA>`))
+	}))
+	defer server.Close()
+	client := nationstates.NewClient(nationstates.WithBaseURL(server.URL))
+	attempts := 0
+	w := &ResolutionWatcher{
+		PollInterval: time.Hour,
+		Client:       client,
+		Councils:     []int{nationstates.GeneralAssembly},
+		StateFile:    filepath.Join(t.TempDir(), "resolutions.json"),
+		Callback: func(council int, resolution nationstates.Resolution) error {
+			attempts++
+			if attempts == 1 {
+				return errors.New("telegram is down")
+			}
+			return nil
+		},
+	}
+	w.poll()
+	w.poll()
+	w.poll()
+	if attempts != 2 {
+		t.Fatalf("got %d announcement attempts, wanted the failed announcement retried once", attempts)
+	}
+}